@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const maxAnswerLength = 1000
+
+func (b *Bot) handleAnswer(post *model.Post, args []string) {
+	text := answerText(post.Message)
+	if len(args) < 2 || text == "" {
+		b.sendReply(post.ChannelId, "Использование: /answer ID_ГОЛОСОВАНИЯ ТЕКСТ")
+		return
+	}
+
+	if len([]rune(text)) > maxAnswerLength {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Ответ слишком длинный: не больше %d символов", maxAnswerLength))
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	if poll.Type != tarantool.PollText {
+		b.sendReply(post.ChannelId, fmt.Sprintf("В этом голосовании выбирают варианты: `/vote %s НОМЕР_ВАРИАНТА`", poll.PollID))
+		return
+	}
+
+	if poll.Status != "active" {
+		b.sendReply(post.ChannelId, "Голосование завершено")
+		return
+	}
+
+	err = b.TarantoolClient.AddAnswer(b.ctx(), tarantool.Answer{
+		PollID:    poll.PollID,
+		UserID:    post.UserId,
+		Text:      text,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		b.postLog(post).Error("Ошибка сохранения ответа", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось сохранить ответ")
+		return
+	}
+
+	b.sendReply(post.ChannelId, "Ваш ответ учтён!")
+}
+
+// answerText возвращает текст ответа без разбора кавычек: всё, что идёт
+// после команды и ID голосования.
+func answerText(message string) string {
+	rest := strings.TrimSpace(message)
+	for i := 0; i < 2; i++ {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return rest
+}
+
+func (b *Bot) handlePromote(post *model.Post, args []string) {
+	flags, args := splitFlagArgs(args)
+	if len(args) < 1 {
+		b.sendReply(post.ChannelId, "Использование: /promote ID_ГОЛОСОВАНИЯ [--type=single|approval|score] [--deadline=24h] [НОМЕРА_ОТВЕТОВ...]")
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	if poll.CreatorID != post.UserId {
+		b.sendReply(post.ChannelId, "Только создатель может выносить ответы на голосование")
+		return
+	}
+
+	if poll.Type != tarantool.PollText {
+		b.sendReply(post.ChannelId, "Выносить на голосование можно только свободные ответы")
+		return
+	}
+
+	answers, err := b.TarantoolClient.GetAnswers(b.ctx(), poll.PollID)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения ответов", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить ответы")
+		return
+	}
+
+	options, err := promotedOptions(answers, args[1:])
+	if err != nil {
+		b.sendReply(post.ChannelId, "Неверный номер ответа")
+		return
+	}
+
+	if len(options) < 2 {
+		b.sendReply(post.ChannelId, "Для голосования нужно хотя бы два разных ответа")
+		return
+	}
+
+	for _, flag := range flags {
+		if strings.HasPrefix(flag, "--type="+tarantool.PollText) {
+			b.sendReply(post.ChannelId, "Новое голосование должно быть с вариантами ответа")
+			return
+		}
+	}
+
+	createArgs := append(flags, poll.Question)
+	b.handleCreatePoll(post, append(createArgs, options...))
+}
+
+// promotedOptions выбирает ответы по номерам из /results (все, если номера
+// не указаны) и убирает повторы.
+func promotedOptions(answers []tarantool.Answer, numbers []string) ([]string, error) {
+	selected := answers
+	if len(numbers) > 0 {
+		selected = make([]tarantool.Answer, 0, len(numbers))
+		for _, number := range numbers {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 1 || n > len(answers) {
+				return nil, tarantool.ErrInvalidOption
+			}
+			selected = append(selected, answers[n-1])
+		}
+	}
+
+	seen := make(map[string]bool, len(selected))
+	var options []string
+	for _, answer := range selected {
+		key := strings.ToLower(answer.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		options = append(options, answer.Text)
+	}
+	return options, nil
+}
+
+// splitFlagArgs отделяет флаги, сохраняя их в исходном виде для передачи дальше.
+func splitFlagArgs(args []string) (flags, rest []string) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			flags = append(flags, arg)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return flags, rest
+}
+
+// formatAnswers выводит свободные ответы; в анонимном голосовании — без авторов.
+func (b *Bot) formatAnswers(poll *tarantool.Poll) (string, error) {
+	answers, err := b.TarantoolClient.GetAnswers(b.ctx(), poll.PollID)
+	if err != nil {
+		return "", err
+	}
+
+	response := fmt.Sprintf("**Ответы**: %s\n", poll.Question)
+	if len(answers) == 0 {
+		return response + "Ответов пока нет", nil
+	}
+
+	usernames := make(map[string]string)
+	if !poll.Anonymous {
+		userIDs := make([]string, len(answers))
+		for i, answer := range answers {
+			userIDs[i] = answer.UserID
+		}
+
+		users, _, err := b.Client.GetUsersByIds(b.ctx(), userIDs)
+		if err != nil {
+			return "", err
+		}
+		for _, user := range users {
+			usernames[user.Id] = user.Username
+		}
+	}
+
+	for i, answer := range answers {
+		response += fmt.Sprintf("%d. %s", i+1, strings.Join(strings.Fields(answer.Text), " "))
+		if username, ok := usernames[answer.UserID]; ok {
+			response += " — @" + username
+		}
+		response += "\n"
+	}
+
+	response += fmt.Sprintf("\nВсего ответов: %d", len(answers))
+	return response, nil
+}
@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"voting-bot/tarantool"
+)
+
+func TestHandleAnswer(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	textPoll := &tarantool.Poll{
+		PollID: "text-poll",
+		Status: "active",
+		Type:   tarantool.PollText,
+	}
+	choicePoll := &tarantool.Poll{
+		PollID:  "choice-poll",
+		Status:  "active",
+		Type:    tarantool.PollSingle,
+		Options: []string{"A", "B"},
+	}
+
+	tests := []struct {
+		name       string
+		message    string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:    "successful answer",
+			message: "/answer text-poll Обсудить  \"релиз\" в пятницу",
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(textPoll, nil)
+				mockTarantool.On("AddAnswer", context.Background(), mock.MatchedBy(func(answer tarantool.Answer) bool {
+					return answer.PollID == "text-poll" &&
+						answer.UserID == "test-user" &&
+						answer.Text == "Обсудить  \"релиз\" в пятницу" &&
+						answer.CreatedAt > 0
+				})).Return(nil)
+			},
+			reply: "Ваш ответ учтён!",
+		},
+		{
+			name:       "missing text",
+			message:    "/answer text-poll",
+			setupMocks: func() {},
+			reply:      "Использование: /answer",
+		},
+		{
+			name:    "choice poll",
+			message: "/answer choice-poll текст",
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "choice-poll").Return(choicePoll, nil)
+			},
+			reply: "В этом голосовании выбирают варианты",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "test-user",
+				ChannelId: "test-channel",
+				Message:   tc.message,
+			}
+
+			bot.handleAnswer(post, splitArgs(tc.message)[1:])
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestHandlePromote(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "text-poll",
+		CreatorID: "creator-user",
+		Question:  "Темы на пятницу?",
+		Status:    "active",
+		Type:      tarantool.PollText,
+	}
+	answers := []tarantool.Answer{
+		{PollID: "text-poll", UserID: "u1", Text: "Релиз"},
+		{PollID: "text-poll", UserID: "u2", Text: "релиз"},
+		{PollID: "text-poll", UserID: "u3", Text: "Онбординг"},
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:   "promote selected answers",
+			userID: "creator-user",
+			args:   []string{"text-poll", "--type=approval", "1", "2", "3"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(poll, nil)
+				mockTarantool.On("GetAnswers", context.Background(), "text-poll").Return(answers, nil)
+				mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(created *tarantool.Poll) bool {
+					return created.Question == "Темы на пятницу?" &&
+						created.Type == tarantool.PollApproval &&
+						assert.ObjectsAreEqual([]string{"Релиз", "Онбординг"}, created.Options)
+				})).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+			},
+			reply: "Голосование создано!",
+		},
+		{
+			name:   "not enough distinct answers",
+			userID: "creator-user",
+			args:   []string{"text-poll", "1", "2"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(poll, nil)
+				mockTarantool.On("GetAnswers", context.Background(), "text-poll").Return(answers, nil)
+			},
+			reply: "Для голосования нужно хотя бы два разных ответа",
+		},
+		{
+			name:   "invalid answer number",
+			userID: "creator-user",
+			args:   []string{"text-poll", "4"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(poll, nil)
+				mockTarantool.On("GetAnswers", context.Background(), "text-poll").Return(answers, nil)
+			},
+			reply: "Неверный номер ответа",
+		},
+		{
+			name:   "non-creator fails",
+			userID: "other-user",
+			args:   []string{"text-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(poll, nil)
+			},
+			reply: "Только создатель может выносить ответы на голосование",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    tc.userID,
+				ChannelId: "test-channel",
+				Message:   "/promote " + strings.Join(tc.args, " "),
+			}
+
+			bot.handlePromote(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestFormatAnswers(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	answers := []tarantool.Answer{
+		{PollID: "text-poll", UserID: "u1", Text: "Больше\nдемо"},
+		{PollID: "text-poll", UserID: "u2", Text: "Меньше встреч"},
+	}
+	mockTarantool.On("GetAnswers", context.Background(), "text-poll").Return(answers, nil)
+	mockMM.On("GetUsersByIds", context.Background(), []string{"u1", "u2"}).Return([]*model.User{
+		{Id: "u1", Username: "alice"},
+		{Id: "u2", Username: "bob"},
+	}, &model.Response{}, nil).Once()
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	poll := &tarantool.Poll{PollID: "text-poll", Question: "Ретро", Type: tarantool.PollText}
+	response, err := bot.formatAnswers(poll)
+	require.NoError(t, err)
+	assert.Contains(t, response, "1. Больше демо — @alice")
+	assert.Contains(t, response, "2. Меньше встреч — @bob")
+	assert.Contains(t, response, "Всего ответов: 2")
+
+	poll.Anonymous = true
+	response, err = bot.formatAnswers(poll)
+	require.NoError(t, err)
+	assert.Contains(t, response, "1. Больше демо\n")
+	assert.NotContains(t, response, "@alice")
+
+	mockMM.AssertExpectations(t)
+}
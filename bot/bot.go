@@ -4,24 +4,71 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/metrics"
 	"voting-bot/tarantool"
 )
 
 type MattermostClient interface {
 	CreatePost(ctx context.Context, post *model.Post) (*model.Post, *model.Response, error)
 	GetMe(ctx context.Context, etag string) (*model.User, *model.Response, error)
+	GetChannelMembers(ctx context.Context, channelId string, page, perPage int, etag string) (model.ChannelMembers, *model.Response, error)
+	CreateDirectChannel(ctx context.Context, userId1, userId2 string) (*model.Channel, *model.Response, error)
+	GetChannelMember(ctx context.Context, channelId, userId, etag string) (*model.ChannelMember, *model.Response, error)
+	GetUserByUsername(ctx context.Context, userName, etag string) (*model.User, *model.Response, error)
+	GetGroups(ctx context.Context, opts model.GroupSearchOpts) ([]*model.Group, *model.Response, error)
+	GetGroupMembers(ctx context.Context, groupID string) (*model.GroupMemberList, *model.Response, error)
+	GetUsersByIds(ctx context.Context, userIds []string) ([]*model.User, *model.Response, error)
+	GetUser(ctx context.Context, userId, etag string) (*model.User, *model.Response, error)
+	SaveReaction(ctx context.Context, reaction *model.Reaction) (*model.Reaction, *model.Response, error)
+	CreatePostEphemeral(ctx context.Context, post *model.PostEphemeral) (*model.Post, *model.Response, error)
+	UploadFile(ctx context.Context, data []byte, channelId string, filename string) (*model.FileUploadResponse, *model.Response, error)
+	PinPost(ctx context.Context, postId string) (*model.Response, error)
+	GetTeamsForUser(ctx context.Context, userId, etag string) ([]*model.Team, *model.Response, error)
+	GetChannelsForTeamForUser(ctx context.Context, teamId, userId string, includeDeleted bool, etag string) ([]*model.Channel, *model.Response, error)
+	GetPostsSince(ctx context.Context, channelId string, time int64, collapsedThreads bool) (*model.PostList, *model.Response, error)
 }
 
 type Bot struct {
 	Client          MattermostClient
-	WebSocket       *model.WebSocketClient
 	TarantoolClient tarantool.Client
 	UserID          string
+	ServerURL       string
+	// ReminderDelay — пауза между личными сообщениями при рассылке напоминаний.
+	ReminderDelay time.Duration
+	// ReconnectDelay и MaxReconnectDelay — начальная и наибольшая пауза между
+	// попытками переподключения к Mattermost.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// MaxReconnectAttempts — неудачных попыток подряд, после которых Listen
+	// возвращает ошибку; 0 — переподключаться бесконечно.
+	MaxReconnectAttempts int
+	// ShutdownTimeout — сколько при остановке ждать завершения начатых команд.
+	ShutdownTimeout time.Duration
+	// Workers — число параллельных обработчиков событий, QueueSize — длина
+	// очереди каждого из них.
+	Workers   int
+	QueueSize int
+	// Logger — журнал бота; если не задан, используется slog.Default().
+	Logger *slog.Logger
+
+	metrics    *metrics.Metrics
+	runs       sync.Map     // ID поста → *commandRun выполняемой команды
+	heartbeat  atomic.Int64 // время последней отметки цикла событий, UnixNano
+	background sync.WaitGroup
+	dial       func() (eventStream, error)
+	conn       connection
+	pool       *workerPool
+	handlerCtx context.Context
 }
 
 func NewBot(serverURL, token string, tc tarantool.Client) (*Bot, error) {
@@ -33,51 +80,72 @@ func NewBot(serverURL, token string, tc tarantool.Client) (*Bot, error) {
 		return nil, err
 	}
 
-	ws, err := model.NewWebSocketClient4(serverURL, token)
-	if err != nil {
-		return nil, err
-	}
-
 	return &Bot{
-		Client:          client,
-		WebSocket:       ws,
-		TarantoolClient: tc,
-		UserID:          user.Id,
+		Client:               client,
+		TarantoolClient:      tc,
+		UserID:               user.Id,
+		ServerURL:            serverURL,
+		ReminderDelay:        defaultReminderDelay,
+		ReconnectDelay:       defaultReconnectDelay,
+		MaxReconnectDelay:    defaultMaxReconnectDelay,
+		MaxReconnectAttempts: DefaultMaxReconnectAttempts,
+		ShutdownTimeout:      DefaultShutdownTimeout,
+		Workers:              DefaultWorkers,
+		QueueSize:            DefaultQueueSize,
+		dial: func() (eventStream, error) {
+			return dialWebSocket(serverURL, token)
+		},
 	}, nil
 }
 
-func (b *Bot) Listen() {
-	b.WebSocket.Listen()
-	go func() {
-		for event := range b.WebSocket.EventChannel {
-			if event.EventType() == model.WebsocketEventPosted {
-				b.handleMessageEvent(event)
-			}
-		}
-	}()
-}
+// parsePostEvent достаёт пост из события о новом сообщении.
+func (b *Bot) parsePostEvent(event *model.WebSocketEvent) (*model.Post, bool) {
+	postData, ok := event.GetData()["post"].(string)
+	if !ok {
+		return nil, false
+	}
 
-func (b *Bot) handleMessageEvent(event *model.WebSocketEvent) {
-	postData := event.GetData()["post"].(string)
 	var post *model.Post
 	if err := json.Unmarshal([]byte(postData), &post); err != nil {
-		log.Printf("Error unmarshaling post: %v", err)
-		return
+		b.logger().Error("Ошибка разбора поста", "err", err)
+		return nil, false
 	}
+	return post, post != nil
+}
 
+// handlePost выполняет команду из поста. Каждая команда выполняется один
+// раз: пост отмечается обработанным, даже если он пришёл повторно при
+// догонке после обрыва соединения.
+func (b *Bot) handlePost(post *model.Post) {
 	if post.UserId == b.UserID {
 		return
 	}
 
 	message := strings.TrimSpace(post.Message)
 	if !strings.HasPrefix(message, "/") {
+		if err := b.TarantoolClient.AdvanceChannelCursor(b.ctx(), post.ChannelId, post.CreateAt); err != nil {
+			b.postLog(post).Error("Ошибка сохранения позиции канала", "err", err)
+		}
 		return
 	}
 
-	parts := strings.Fields(message)
+	claimed, err := b.TarantoolClient.ClaimPost(b.ctx(), post.Id, post.ChannelId, post.CreateAt)
+	if err != nil {
+		b.postLog(post).Error("Ошибка отметки поста", "err", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	parts := splitArgs(message)
 	command := parts[0]
 	args := parts[1:]
 
+	run := &commandRun{}
+	b.runs.Store(post.Id, run)
+	defer b.finishCommand(post, command, args, run, time.Now())
+
 	switch command {
 	case "/createpoll":
 		b.handleCreatePoll(post, args)
@@ -89,59 +157,301 @@ func (b *Bot) handleMessageEvent(event *model.WebSocketEvent) {
 		b.handleEndPoll(post, args)
 	case "/deletepoll":
 		b.handleDeletePoll(post, args)
+	case "/remind":
+		b.handleRemind(post, args)
+	case "/weight":
+		b.handleWeight(post, args)
+	case "/delegate":
+		b.handleDelegate(post, args)
+	case "/undelegate":
+		b.handleUndelegate(post, args)
+	case "/answer":
+		b.handleAnswer(post, args)
+	case "/promote":
+		b.handlePromote(post, args)
+	case "/schedule":
+		b.handleSchedule(post, args)
+	case "/leaderboard":
+		b.handleLeaderboard(post, args)
+	case "/unvote":
+		b.handleUnvote(post, args)
+	case "/template":
+		b.handleTemplate(post, args)
+	case "/clonepoll":
+		b.handleClonePoll(post, args)
+	case "/schedulepoll":
+		b.handleSchedulePoll(post, args)
+	case "/schedules":
+		b.handleSchedules(post, args)
+	case "/export":
+		b.handleExport(post, args)
+	default:
+		run.unknown = true
 	}
+	run.completed = true
 }
 
+// createPollFlags — флаги, которые понимает /createpoll.
+var createPollFlags = []string{"deadline", "remind", "quorum", "changes", "type", "scale", "correct", "anonymous", "pin"}
+
+const createPollUsage = "Использование: /createpoll [--deadline=24h] [--remind=1h] [--quorum=10|50%] [--changes=allowed|locked|30m] [--type=single|approval|score] [--scale=0-5] [--anonymous] [--pin] \"Вопрос?\" \"Вариант1\" \"Вариант2\" ...\n" +
+	"Свободные ответы: /createpoll --type=text [--anonymous] \"Вопрос?\"\n" +
+	"Викторина: /createpoll --type=quiz --correct=2 \"Вопрос?\" \"Вариант1\" \"Вариант2\" ...\n" +
+	"--anonymous скрывает имена проголосовавших, --pin закрепляет итоги в канале после завершения"
+
 func (b *Bot) handleCreatePoll(post *model.Post, args []string) {
-	if len(args) < 2 {
-		b.sendReply(post.ChannelId, "Использование: /createpoll \"Вопрос?\" \"Вариант1\" \"Вариант2\" ...")
+	flags, args, err := parseFlags(args, createPollFlags)
+	if err != nil {
+		b.sendReply(post.ChannelId, err.Error()+"\n"+createPollUsage)
+		return
+	}
+	isText := flags["type"] == tarantool.PollText
+	if len(args) < 1 || (!isText && len(args) < 2) || (isText && len(args) > 1) {
+		b.sendReply(post.ChannelId, createPollUsage)
 		return
 	}
 
-	question := args[0]
-	options := args[1:]
-	pollID := model.NewId()
+	now := time.Now()
+	poll := &tarantool.Poll{
+		PollID:    model.NewId(),
+		CreatorID: post.UserId,
+		Question:  args[0],
+		Options:   args[1:],
+		ChannelID: post.ChannelId,
+		CreatedAt: now.Unix(),
+		Type:      tarantool.PollSingle,
+	}
 
-	err := b.TarantoolClient.CreatePoll(context.Background(), pollID, post.UserId, question, options)
-	if err != nil {
-		log.Printf("Ошибка создания голосования: %v", err)
-		b.sendReply(post.ChannelId, "Не удалось создать голосование")
+	if value, ok := flags["type"]; ok {
+		switch value {
+		case tarantool.PollSingle, tarantool.PollApproval, tarantool.PollText:
+		case tarantool.PollScore:
+			poll.ScoreMin, poll.ScoreMax = 0, 5
+		case tarantool.PollQuiz:
+			correct, err := parseCorrect(flags["correct"], len(poll.Options))
+			if err != nil {
+				b.sendReply(post.ChannelId, "Укажите правильные варианты викторины: --correct=2 или --correct=1,3")
+				return
+			}
+			poll.Correct = correct
+		case tarantool.PollSchedule:
+			for _, option := range poll.Options {
+				if _, err := slotFromOption(option); err != nil {
+					b.sendReply(post.ChannelId, "Для выбора времени используйте /schedule")
+					return
+				}
+			}
+		default:
+			b.sendReply(post.ChannelId, "Неизвестный тип голосования: доступны single, approval, score, text и quiz")
+			return
+		}
+		poll.Type = value
+	}
+
+	if _, ok := flags["correct"]; ok && poll.Type != tarantool.PollQuiz {
+		b.sendReply(post.ChannelId, "Правильные варианты задаются только для --type=quiz")
+		return
+	}
+
+	if _, ok := flags["anonymous"]; ok {
+		poll.Anonymous = true
+	}
+
+	if _, ok := flags["pin"]; ok {
+		poll.PinResults = true
+	}
+
+	if value, ok := flags["scale"]; ok {
+		scoreMin, scoreMax, err := tarantool.ParseScale(value)
+		if err != nil || poll.Type != tarantool.PollScore {
+			b.sendReply(post.ChannelId, "Шкала задаётся только для --type=score в виде 0-5 или 1-10")
+			return
+		}
+		poll.ScoreMin, poll.ScoreMax = scoreMin, scoreMax
+	}
+
+	if value, ok := flags["deadline"]; ok {
+		deadline, err := parseDeadline(value, now)
+		if err != nil {
+			b.sendReply(post.ChannelId, "Неверный срок голосования: укажите длительность (24h) или дату (2006-01-02T15:04)")
+			return
+		}
+		poll.Deadline = deadline.Unix()
+	}
+
+	var remindBefore time.Duration
+	if value, ok := flags["remind"]; ok {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 || poll.Deadline == 0 {
+			b.sendReply(post.ChannelId, "Напоминание задаётся длительностью (1h) и только вместе с --deadline")
+			return
+		}
+		remindBefore = d
+	}
+
+	if value, ok := flags["changes"]; ok {
+		if err := parseVoteChange(poll, value); err != nil {
+			b.sendReply(post.ChannelId, "Неверная политика изменения голоса: allowed, locked или длительность до срока (30m) вместе с --deadline")
+			return
+		}
+	}
+
+	if value, ok := flags["quorum"]; ok {
+		count, percent, err := parseQuorum(value)
+		if err != nil {
+			b.sendReply(post.ChannelId, "Неверный кворум: укажите число голосов (10) или процент участников канала (50%)")
+			return
+		}
+
+		members, err := b.channelMembers(post.ChannelId)
+		if err != nil {
+			b.postLog(post).Error("Ошибка получения участников канала", "err", err)
+			b.sendReply(post.ChannelId, "Не удалось получить список участников канала")
+			return
+		}
+
+		poll.Eligible = len(members)
+		poll.Quorum = count
+		poll.QuorumPercent = percent
+		if percent > 0 {
+			poll.Quorum = quorumOf(poll.Eligible, percent)
+		}
+	}
+
+	if !b.publishPoll(poll) {
 		return
 	}
 
-	response := fmt.Sprintf("Голосование создано! ID: `%s`\n**Вопрос**: %s\n**Варианты**:\n", pollID, question)
-	for i, opt := range options {
-		response += fmt.Sprintf("%d. %s\n", i+1, opt)
+	if remindBefore > 0 {
+		b.scheduleReminder(post.ChannelId, poll, remindBefore)
 	}
-	b.sendReply(post.ChannelId, response)
+}
+
+// publishPoll сохраняет голосование и публикует его в канале poll.ChannelID.
+func (b *Bot) publishPoll(poll *tarantool.Poll) bool {
+	logger := b.logger().With("poll_id", poll.PollID, "channel_id", poll.ChannelID)
+	err := b.TarantoolClient.CreatePoll(b.ctx(), poll)
+	if err != nil {
+		logger.Error("Ошибка создания голосования", "err", err)
+		b.sendReply(poll.ChannelID, "Не удалось создать голосование")
+		return false
+	}
+	b.metrics.PollCreated()
+
+	response := fmt.Sprintf("Голосование создано! ID: `%s`\n**Вопрос**: %s\n", poll.PollID, poll.Question)
+	if poll.Type == tarantool.PollText {
+		response += fmt.Sprintf("Отвечайте командой: `/answer %s ТЕКСТ`\n", poll.PollID)
+		if poll.Anonymous {
+			response += "Ответы публикуются анонимно\n"
+		}
+	} else {
+		options := poll.Options
+		if poll.Type == tarantool.PollSchedule {
+			loc := b.userLocation(poll.CreatorID)
+			options = formatSlots(poll.Options, loc)
+			response += fmt.Sprintf("**Часовой пояс**: %s\n", loc)
+		}
+
+		response += "**Варианты**:\n"
+		for i, opt := range options {
+			response += fmt.Sprintf("%d. %s\n", i+1, opt)
+		}
+	}
+	if poll.Deadline != 0 {
+		response += fmt.Sprintf("**Срок**: %s\n", time.Unix(poll.Deadline, 0).Format("02.01.2006 15:04"))
+	}
+	if poll.Quorum > 0 {
+		response += fmt.Sprintf("**Кворум**: %d из %d участников\n", poll.Quorum, poll.Eligible)
+	}
+	switch poll.VoteChange {
+	case tarantool.VoteChangeLocked:
+		response += "**Изменение голоса**: запрещено, первый голос окончательный\n"
+	case tarantool.VoteChangeUntil:
+		response += fmt.Sprintf("**Изменение голоса**: до %s\n", time.Unix(poll.Deadline-poll.ChangeCutoff, 0).Format("02.01.2006 15:04"))
+	}
+	switch poll.Type {
+	case tarantool.PollApproval:
+		response += fmt.Sprintf("Можно одобрить несколько вариантов: `/vote %s 1 3`\n", poll.PollID)
+	case tarantool.PollScore:
+		response += fmt.Sprintf("Оцените варианты от %d до %d: `/vote %s 1=%d 2=%d`\n", poll.ScoreMin, poll.ScoreMax, poll.PollID, poll.ScoreMax, poll.ScoreMin)
+	case tarantool.PollQuiz:
+		if len(poll.Correct) > 1 {
+			response += fmt.Sprintf("Викторина: выберите все правильные варианты (`/vote %s 1 3`), ответ откроется после завершения\n", poll.PollID)
+		} else {
+			response += "Викторина: правильный ответ откроется после завершения\n"
+		}
+	case tarantool.PollSchedule:
+		response += fmt.Sprintf("Отметьте, когда можете: `/vote %s 1=да 2=может 3=нет`\n"+
+			"`/results %s` покажет время в вашем часовом поясе\n", poll.PollID, poll.PollID)
+	}
+	if votesByReaction(poll) {
+		response += "Можно голосовать реакциями с номером варианта под этим сообщением\n"
+	}
+	if poll.PinResults {
+		response += "Итоги будут закреплены в канале после завершения\n"
+	}
+
+	created, err := b.createPost(poll.ChannelID, response)
+	if err != nil {
+		logger.Error("Ошибка отправки сообщения", "err", err)
+		return false
+	}
+	logger.Info("Голосование создано", "user_id", poll.CreatorID, "type", poll.Type)
+
+	if err := b.TarantoolClient.SetPollPost(b.ctx(), poll.PollID, created.Id); err != nil {
+		logger.Error("Ошибка сохранения поста голосования", "err", err)
+	} else if votesByReaction(poll) {
+		b.seedReactions(poll, created.Id)
+	}
+	return true
 }
 
 func (b *Bot) handleVote(post *model.Post, args []string) {
-	if len(args) != 2 {
-		b.sendReply(post.ChannelId, "Использование: /vote ID_ГОЛОСОВАНИЯ НОМЕР_ВАРИАНТА")
+	if len(args) < 2 {
+		b.sendReply(post.ChannelId, "Использование: /vote ID_ГОЛОСОВАНИЯ НОМЕР_ВАРИАНТА\n"+
+			"Одобрение: /vote ID_ГОЛОСОВАНИЯ 1 3\nОценки: /vote ID_ГОЛОСОВАНИЯ 1=5 2=3")
 		return
 	}
 
 	pollID := args[0]
-	option := args[1]
 
-	poll, err := b.TarantoolClient.GetPoll(context.Background(), pollID)
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
 	if err != nil || poll == nil {
 		b.sendReply(post.ChannelId, "Голосование не найдено")
 		return
 	}
 
-	if optionNum, err := strconv.Atoi(option); err != nil || optionNum < 1 || optionNum > len(poll.Options) {
-		b.sendReply(post.ChannelId, "Неверный номер варианта")
+	if poll.Type == tarantool.PollText {
+		b.sendReply(post.ChannelId, fmt.Sprintf("В этом голосовании нужно ответить текстом: `/answer %s ТЕКСТ`", poll.PollID))
+		return
+	}
+
+	option, err := poll.EncodeBallot(args[1:])
+	if err != nil {
+		switch poll.Type {
+		case tarantool.PollApproval:
+			b.sendReply(post.ChannelId, "Неверные номера вариантов, например: /vote ID 1 3")
+		case tarantool.PollSchedule:
+			b.sendReply(post.ChannelId, "Неверные ответы: укажите НОМЕР=да|может|нет, например: /vote ID 1=да 2=может")
+		case tarantool.PollScore:
+			b.sendReply(post.ChannelId, fmt.Sprintf("Неверные оценки: укажите НОМЕР=ОЦЕНКА от %d до %d, например: /vote ID 1=%d", poll.ScoreMin, poll.ScoreMax, poll.ScoreMax))
+		default:
+			b.sendReply(post.ChannelId, "Неверный номер варианта")
+		}
 		return
 	}
 
-	err = b.TarantoolClient.AddVote(context.Background(), pollID, post.UserId, option)
+	err = b.TarantoolClient.AddVote(b.ctx(), pollID, post.UserId, option)
+	if reply, ok := voteErrorReply(err); ok {
+		b.sendReply(post.ChannelId, reply)
+		return
+	}
 	if err != nil {
-		log.Printf("Ошибка голосования: %v", err)
+		b.postLog(post).Error("Ошибка голосования", "err", err)
 		b.sendReply(post.ChannelId, "Не удалось сохранить ваш голос")
 		return
 	}
+	b.metrics.VoteCast()
 
 	b.sendReply(post.ChannelId, "Ваш голос учтён!")
 }
@@ -154,18 +464,50 @@ func (b *Bot) handleResults(post *model.Post, args []string) {
 
 	pollID := args[0]
 
-	results, err := b.TarantoolClient.GetResults(context.Background(), pollID)
-	if err != nil || results == nil {
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
+	if err != nil || poll == nil {
 		b.sendReply(post.ChannelId, "Голосование не найдено")
 		return
 	}
 
-	response := fmt.Sprintf("**Результаты голосования**: %s\n", results.Question)
-	for i, opt := range results.Options {
-		response += fmt.Sprintf("%d. %s - %d голосов\n", i+1, opt, results.Votes[i])
+	if poll.Status == "closed" {
+		if summary, ok := b.finalSummary(poll); ok {
+			b.sendReply(post.ChannelId, summary)
+			return
+		}
+	}
+
+	// Время слотов каждый видит в своём часовом поясе, поэтому ответ личный
+	if poll.Type == tarantool.PollSchedule {
+		response, err := b.formatSchedule(poll, b.userLocation(post.UserId))
+		if err != nil {
+			b.postLog(post).Error("Ошибка получения результатов", "err", err)
+			b.sendReply(post.ChannelId, "Не удалось получить результаты")
+			return
+		}
+		b.sendEphemeral(post.ChannelId, post.UserId, response)
+		return
+	}
+
+	if poll.Type == tarantool.PollText {
+		response, err := b.formatAnswers(poll)
+		if err != nil {
+			b.postLog(post).Error("Ошибка получения результатов", "err", err)
+			b.sendReply(post.ChannelId, "Не удалось получить результаты")
+			return
+		}
+		b.sendReply(post.ChannelId, response)
+		return
+	}
+
+	results, err := b.pollResults(poll)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения результатов", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить результаты")
+		return
 	}
-	response += fmt.Sprintf("\nВсего голосов: %d", results.Total)
-	b.sendReply(post.ChannelId, response)
+
+	b.sendResults(post.ChannelId, poll.PollID, results)
 }
 
 func (b *Bot) handleEndPoll(post *model.Post, args []string) {
@@ -176,7 +518,7 @@ func (b *Bot) handleEndPoll(post *model.Post, args []string) {
 
 	pollID := args[0]
 
-	poll, err := b.TarantoolClient.GetPoll(context.Background(), pollID)
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
 	if err != nil || poll == nil {
 		b.sendReply(post.ChannelId, "Голосование не найдено")
 		return
@@ -187,14 +529,33 @@ func (b *Bot) handleEndPoll(post *model.Post, args []string) {
 		return
 	}
 
-	err = b.TarantoolClient.UpdatePollStatus(context.Background(), pollID, "closed")
+	if poll.Status != "active" {
+		b.sendReply(post.ChannelId, "Голосование уже завершено")
+		return
+	}
+
+	closed, err := b.TarantoolClient.ClosePoll(b.ctx(), pollID)
 	if err != nil {
-		log.Printf("Ошибка завершения голосования: %v", err)
+		b.postLog(post).Error("Ошибка завершения голосования", "err", err)
 		b.sendReply(post.ChannelId, "Не удалось завершить голосование")
 		return
 	}
+	// Голосование успел закрыть планировщик, итоги объявит он
+	if !closed {
+		b.sendReply(post.ChannelId, "Голосование уже завершено")
+		return
+	}
+	b.metrics.PollClosed()
 
-	b.sendReply(post.ChannelId, "Голосование завершено!")
+	if poll.Type == tarantool.PollQuiz {
+		b.finishQuiz(poll)
+	}
+
+	channelId := poll.ChannelID
+	if channelId == "" {
+		channelId = post.ChannelId
+	}
+	b.announceFinalResults(poll, channelId, "Голосование завершено!")
 }
 
 func (b *Bot) handleDeletePoll(post *model.Post, args []string) {
@@ -205,7 +566,7 @@ func (b *Bot) handleDeletePoll(post *model.Post, args []string) {
 
 	pollID := args[0]
 
-	poll, err := b.TarantoolClient.GetPoll(context.Background(), pollID)
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
 	if err != nil || poll == nil {
 		b.sendReply(post.ChannelId, "Голосование не найдено")
 		return
@@ -216,9 +577,9 @@ func (b *Bot) handleDeletePoll(post *model.Post, args []string) {
 		return
 	}
 
-	err = b.TarantoolClient.DeletePoll(context.Background(), pollID)
+	err = b.TarantoolClient.DeletePoll(b.ctx(), pollID)
 	if err != nil {
-		log.Printf("Ошибка удаления голосования: %v", err)
+		b.postLog(post).Error("Ошибка удаления голосования", "err", err)
 		b.sendReply(post.ChannelId, "Не удалось удалить голосование")
 		return
 	}
@@ -227,12 +588,119 @@ func (b *Bot) handleDeletePoll(post *model.Post, args []string) {
 }
 
 func (b *Bot) sendReply(channelId, message string) {
+	if _, err := b.createPost(channelId, message); err != nil {
+		b.logger().Error("Ошибка отправки сообщения", "err", err)
+	}
+}
+
+// sendEphemeral показывает сообщение в канале только пользователю userId.
+func (b *Bot) sendEphemeral(channelId, userId, message string) {
+	_, _, err := b.Client.CreatePostEphemeral(b.ctx(), &model.PostEphemeral{
+		UserID: userId,
+		Post: &model.Post{
+			ChannelId: channelId,
+			Message:   message,
+		},
+	})
+	if err != nil {
+		b.logger().Error("Ошибка отправки сообщения", "err", err)
+	}
+}
+
+func (b *Bot) createPost(channelId, message string) (*model.Post, error) {
 	post := &model.Post{
 		ChannelId: channelId,
 		Message:   message,
 	}
 
-	if _, _, err := b.Client.CreatePost(context.Background(), post); err != nil {
-		log.Printf("Ошибка отправки сообщения: %v", err)
+	created, _, err := b.Client.CreatePost(b.ctx(), post)
+	return created, err
+}
+
+// splitArgs разбивает команду на аргументы; текст в кавычках ("", «», “”)
+// считается одним аргументом.
+func splitArgs(message string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for _, r := range message {
+		switch {
+		case strings.ContainsRune(`"«»“”`, r):
+			inQuotes = !inQuotes
+			hasArg = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// parseFlags отделяет ведущие аргументы вида --name=value от остальных.
+// Флаг не из known — ошибка: опечатка не должна молча менять голосование.
+func parseFlags(args []string, known []string) (map[string]string, []string, error) {
+	flags := make(map[string]string)
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, _ := strings.Cut(strings.TrimPrefix(args[0], "--"), "=")
+		if !slices.Contains(known, name) {
+			return nil, nil, fmt.Errorf("Неизвестный флаг --%s", name)
+		}
+		flags[name] = value
+		args = args[1:]
+	}
+	return flags, args, nil
+}
+
+// quorumOf возвращает число голосов, составляющее percent процентов от
+// eligible участников, с округлением вверх.
+func quorumOf(eligible, percent int) int {
+	return (eligible*percent + 99) / 100
+}
+
+// parseQuorum разбирает кворум в виде абсолютного числа голосов (10)
+// или процента участников канала (50%).
+func parseQuorum(value string) (count, percent int, err error) {
+	if strings.HasSuffix(value, "%") {
+		percent, err = strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent < 1 || percent > 100 {
+			return 0, 0, fmt.Errorf("invalid quorum percent %q", value)
+		}
+		return 0, percent, nil
+	}
+
+	count, err = strconv.Atoi(value)
+	if err != nil || count < 1 {
+		return 0, 0, fmt.Errorf("invalid quorum %q", value)
+	}
+	return count, 0, nil
+}
+
+// parseDeadline принимает длительность от текущего момента (24h, 90m)
+// или локальную дату в формате 2006-01-02T15:04.
+func parseDeadline(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("non-positive duration %q", value)
+		}
+		return now.Add(d), nil
+	}
+
+	deadline, err := time.ParseInLocation("2006-01-02T15:04", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !deadline.After(now) {
+		return time.Time{}, fmt.Errorf("deadline %q is in the past", value)
 	}
+	return deadline, nil
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"voting-bot/tarantool"
 )
@@ -14,8 +16,8 @@ type MockTarantool struct {
 	mock.Mock
 }
 
-func (m *MockTarantool) CreatePoll(ctx context.Context, pollID, creatorID, question string, options []string) error {
-	args := m.Called(ctx, pollID, creatorID, question, options)
+func (m *MockTarantool) CreatePoll(ctx context.Context, poll *tarantool.Poll) error {
+	args := m.Called(ctx, poll)
 	return args.Error(0)
 }
 
@@ -27,11 +29,45 @@ func (m *MockTarantool) GetPoll(ctx context.Context, pollID string) (*tarantool.
 	return args.Get(0).(*tarantool.Poll), args.Error(1)
 }
 
+func (m *MockTarantool) GetPollByPost(ctx context.Context, postID string) (*tarantool.Poll, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tarantool.Poll), args.Error(1)
+}
+
+func (m *MockTarantool) SetPollPost(ctx context.Context, pollID, postID string) error {
+	args := m.Called(ctx, pollID, postID)
+	return args.Error(0)
+}
+
 func (m *MockTarantool) AddVote(ctx context.Context, pollID, userID, option string) error {
 	args := m.Called(ctx, pollID, userID, option)
 	return args.Error(0)
 }
 
+func (m *MockTarantool) GetVote(ctx context.Context, pollID, userID string) (*tarantool.Vote, error) {
+	args := m.Called(ctx, pollID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tarantool.Vote), args.Error(1)
+}
+
+func (m *MockTarantool) DeleteVote(ctx context.Context, pollID, userID string) error {
+	args := m.Called(ctx, pollID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetVoters(ctx context.Context, pollID string) ([]string, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockTarantool) GetResults(ctx context.Context, pollID string) (*tarantool.VoteResult, error) {
 	args := m.Called(ctx, pollID)
 	if args.Get(0) == nil {
@@ -45,11 +81,210 @@ func (m *MockTarantool) UpdatePollStatus(ctx context.Context, pollID, status str
 	return args.Error(0)
 }
 
+func (m *MockTarantool) ClosePoll(ctx context.Context, pollID string) (bool, error) {
+	args := m.Called(ctx, pollID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockTarantool) DeletePoll(ctx context.Context, pollID string) error {
 	args := m.Called(ctx, pollID)
 	return args.Error(0)
 }
 
+func (m *MockTarantool) AddReminder(ctx context.Context, pollID string, remindAt int64) error {
+	args := m.Called(ctx, pollID, remindAt)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetDueReminders(ctx context.Context, now int64) ([]tarantool.Reminder, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Reminder), args.Error(1)
+}
+
+func (m *MockTarantool) ClaimReminder(ctx context.Context, pollID string, remindAt int64) (bool, error) {
+	args := m.Called(ctx, pollID, remindAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTarantool) GetExpiredPolls(ctx context.Context, now int64) ([]*tarantool.Poll, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*tarantool.Poll), args.Error(1)
+}
+
+func (m *MockTarantool) GetVotes(ctx context.Context, pollID string) ([]tarantool.Vote, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Vote), args.Error(1)
+}
+
+func (m *MockTarantool) SetWeight(ctx context.Context, weight tarantool.Weight) error {
+	args := m.Called(ctx, weight)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) DeleteWeight(ctx context.Context, scope, subject string) error {
+	args := m.Called(ctx, scope, subject)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetWeights(ctx context.Context, scope string) ([]tarantool.Weight, error) {
+	args := m.Called(ctx, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Weight), args.Error(1)
+}
+
+func (m *MockTarantool) SetDelegation(ctx context.Context, delegation tarantool.Delegation) error {
+	args := m.Called(ctx, delegation)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) DeleteDelegation(ctx context.Context, scope, fromUser string) error {
+	args := m.Called(ctx, scope, fromUser)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetDelegations(ctx context.Context, scope string) ([]tarantool.Delegation, error) {
+	args := m.Called(ctx, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Delegation), args.Error(1)
+}
+
+func (m *MockTarantool) AddAnswer(ctx context.Context, answer tarantool.Answer) error {
+	args := m.Called(ctx, answer)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetAnswers(ctx context.Context, pollID string) ([]tarantool.Answer, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Answer), args.Error(1)
+}
+
+func (m *MockTarantool) SaveTemplate(ctx context.Context, template tarantool.Template) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetTemplate(ctx context.Context, name string) (*tarantool.Template, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tarantool.Template), args.Error(1)
+}
+
+func (m *MockTarantool) GetTemplates(ctx context.Context) ([]tarantool.Template, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Template), args.Error(1)
+}
+
+func (m *MockTarantool) SaveQuizResult(ctx context.Context, result tarantool.QuizResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetQuizResults(ctx context.Context, channelID string) ([]tarantool.QuizResult, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.QuizResult), args.Error(1)
+}
+
+func (m *MockTarantool) SaveFinalResult(ctx context.Context, result tarantool.FinalResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetFinalResult(ctx context.Context, pollID string) (*tarantool.FinalResult, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tarantool.FinalResult), args.Error(1)
+}
+
+func (m *MockTarantool) AddRecurrence(ctx context.Context, recurrence tarantool.Recurrence) error {
+	args := m.Called(ctx, recurrence)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetRecurrence(ctx context.Context, id string) (*tarantool.Recurrence, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tarantool.Recurrence), args.Error(1)
+}
+
+func (m *MockTarantool) GetRecurrences(ctx context.Context, channelID string) ([]tarantool.Recurrence, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Recurrence), args.Error(1)
+}
+
+func (m *MockTarantool) GetDueRecurrences(ctx context.Context, now int64) ([]tarantool.Recurrence, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tarantool.Recurrence), args.Error(1)
+}
+
+func (m *MockTarantool) ClaimRecurrence(ctx context.Context, id string, expected, next int64) (bool, error) {
+	args := m.Called(ctx, id, expected, next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTarantool) ClaimPost(ctx context.Context, postID, channelID string, createdAt int64) (bool, error) {
+	args := m.Called(ctx, postID, channelID, createdAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTarantool) GetChannelCursor(ctx context.Context, channelID string) (int64, error) {
+	args := m.Called(ctx, channelID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTarantool) AdvanceChannelCursor(ctx context.Context, channelID string, lastPostAt int64) error {
+	args := m.Called(ctx, channelID, lastPostAt)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockTarantool) GetSchemaVersion(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTarantool) DeleteRecurrence(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockTarantool) Close() error {
 	return nil
 }
@@ -68,6 +303,84 @@ func (m *MockMattermostClient) GetMe(ctx context.Context, etag string) (*model.U
 	return args.Get(0).(*model.User), args.Get(1).(*model.Response), args.Error(2)
 }
 
+func (m *MockMattermostClient) GetChannelMembers(ctx context.Context, channelId string, page, perPage int, etag string) (model.ChannelMembers, *model.Response, error) {
+	args := m.Called(ctx, channelId, page, perPage, etag)
+	return args.Get(0).(model.ChannelMembers), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) CreateDirectChannel(ctx context.Context, userId1, userId2 string) (*model.Channel, *model.Response, error) {
+	args := m.Called(ctx, userId1, userId2)
+	return args.Get(0).(*model.Channel), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetChannelMember(ctx context.Context, channelId, userId, etag string) (*model.ChannelMember, *model.Response, error) {
+	args := m.Called(ctx, channelId, userId, etag)
+	return args.Get(0).(*model.ChannelMember), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetUserByUsername(ctx context.Context, userName, etag string) (*model.User, *model.Response, error) {
+	args := m.Called(ctx, userName, etag)
+	return args.Get(0).(*model.User), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetGroups(ctx context.Context, opts model.GroupSearchOpts) ([]*model.Group, *model.Response, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]*model.Group), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetGroupMembers(ctx context.Context, groupID string) (*model.GroupMemberList, *model.Response, error) {
+	args := m.Called(ctx, groupID)
+	return args.Get(0).(*model.GroupMemberList), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetUser(ctx context.Context, userId, etag string) (*model.User, *model.Response, error) {
+	args := m.Called(ctx, userId, etag)
+	return args.Get(0).(*model.User), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) CreatePostEphemeral(ctx context.Context, post *model.PostEphemeral) (*model.Post, *model.Response, error) {
+	args := m.Called(ctx, post)
+	return args.Get(0).(*model.Post), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) UploadFile(ctx context.Context, data []byte, channelId string, filename string) (*model.FileUploadResponse, *model.Response, error) {
+	args := m.Called(ctx, data, channelId, filename)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(*model.Response), args.Error(2)
+	}
+	return args.Get(0).(*model.FileUploadResponse), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetTeamsForUser(ctx context.Context, userId, etag string) ([]*model.Team, *model.Response, error) {
+	args := m.Called(ctx, userId, etag)
+	return args.Get(0).([]*model.Team), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetChannelsForTeamForUser(ctx context.Context, teamId, userId string, includeDeleted bool, etag string) ([]*model.Channel, *model.Response, error) {
+	args := m.Called(ctx, teamId, userId, includeDeleted, etag)
+	return args.Get(0).([]*model.Channel), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetPostsSince(ctx context.Context, channelId string, time int64, collapsedThreads bool) (*model.PostList, *model.Response, error) {
+	args := m.Called(ctx, channelId, time, collapsedThreads)
+	return args.Get(0).(*model.PostList), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) PinPost(ctx context.Context, postId string) (*model.Response, error) {
+	args := m.Called(ctx, postId)
+	return args.Get(0).(*model.Response), args.Error(1)
+}
+
+func (m *MockMattermostClient) SaveReaction(ctx context.Context, reaction *model.Reaction) (*model.Reaction, *model.Response, error) {
+	args := m.Called(ctx, reaction)
+	return args.Get(0).(*model.Reaction), args.Get(1).(*model.Response), args.Error(2)
+}
+
+func (m *MockMattermostClient) GetUsersByIds(ctx context.Context, userIds []string) ([]*model.User, *model.Response, error) {
+	args := m.Called(ctx, userIds)
+	return args.Get(0).([]*model.User), args.Get(1).(*model.Response), args.Error(2)
+}
+
 func TestHandleCreatePoll(t *testing.T) {
 	mockTarantool := new(MockTarantool)
 	mockMM := new(MockMattermostClient)
@@ -85,11 +398,19 @@ func TestHandleCreatePoll(t *testing.T) {
 				mockTarantool.On(
 					"CreatePoll",
 					context.Background(),
-					mock.AnythingOfType("string"),
-					"test-user",
-					"Test question?",
-					[]string{"Option1", "Option2"},
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.PollID != "" &&
+							poll.CreatorID == "test-user" &&
+							poll.Question == "Test question?" &&
+							poll.ChannelID == "test-channel" &&
+							poll.Deadline == 0 &&
+							assert.ObjectsAreEqual([]string{"Option1", "Option2"}, poll.Options)
+					}),
 				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				for _, emoji := range []string{"one", "two"} {
+					mockMM.On("SaveReaction", context.Background(), &model.Reaction{UserId: "bot-user", PostId: "poll-post", EmojiName: emoji}).Return(&model.Reaction{}, &model.Response{}, nil).Once()
+				}
 
 				mockMM.On(
 					"CreatePost",
@@ -100,8 +421,194 @@ func TestHandleCreatePoll(t *testing.T) {
 							strings.Contains(post.Message, "1. Option1") &&
 							strings.Contains(post.Message, "2. Option2")
 					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "deadline with reminder",
+			args: []string{"--deadline=24h", "--remind=1h", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockTarantool.On(
+					"CreatePoll",
+					context.Background(),
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.Question == "Test question?" &&
+							poll.Deadline > time.Now().Add(23*time.Hour).Unix()
+					}),
+				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+				mockTarantool.On("AddReminder", context.Background(), mock.AnythingOfType("string"), mock.MatchedBy(func(remindAt int64) bool {
+					return remindAt > time.Now().Add(22*time.Hour).Unix() && remindAt < time.Now().Add(24*time.Hour).Unix()
+				})).Return(nil)
+
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Голосование создано!")
+					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Напоминание запланировано")
+					}),
+				).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "percent quorum",
+			args: []string{"--quorum=50%", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(model.ChannelMembers{
+					{UserId: "test-user"},
+					{UserId: "user1"},
+					{UserId: "user2"},
+					{UserId: "bot-user"},
+				}, &model.Response{}, nil)
+				mockTarantool.On(
+					"CreatePoll",
+					context.Background(),
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.Quorum == 2 && poll.QuorumPercent == 50 && poll.Eligible == 3
+					}),
+				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "**Кворум**: 2 из 3 участников")
+					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "score poll",
+			args: []string{"--type=score", "--scale=1-10", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockTarantool.On(
+					"CreatePoll",
+					context.Background(),
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.Type == tarantool.PollScore && poll.ScoreMin == 1 && poll.ScoreMax == 10
+					}),
+				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Оцените варианты от 1 до 10")
+					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "scale without score type",
+			args: []string{"--scale=1-10", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Шкала задаётся только для --type=score")
+					}),
+				).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name: "reminder without deadline",
+			args: []string{"--remind=1h", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "только вместе с --deadline")
+					}),
+				).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name: "locked votes",
+			args: []string{"--changes=locked", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockTarantool.On(
+					"CreatePoll",
+					context.Background(),
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.VoteChange == tarantool.VoteChangeLocked
+					}),
+				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "**Изменение голоса**: запрещено")
+					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "pin results",
+			args: []string{"--pin", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockTarantool.On(
+					"CreatePoll",
+					context.Background(),
+					mock.MatchedBy(func(poll *tarantool.Poll) bool {
+						return poll.PinResults
+					}),
+				).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Итоги будут закреплены в канале")
+					}),
+				).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "change cutoff without deadline",
+			args: []string{"--changes=30m", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Неверная политика изменения голоса")
+					}),
 				).Return(&model.Post{}, &model.Response{}, nil)
 			},
+			expectError: true,
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--deadlin=1h", "Test question?", "Option1", "Option2"},
+			setupMocks: func() {
+				mockMM.On(
+					"CreatePost",
+					context.Background(),
+					mock.MatchedBy(func(post *model.Post) bool {
+						return strings.Contains(post.Message, "Неизвестный флаг --deadlin") &&
+							strings.Contains(post.Message, "[--pin]")
+					}),
+				).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
 		},
 		{
 			name: "insufficient arguments",
@@ -126,7 +633,7 @@ func TestHandleCreatePoll(t *testing.T) {
 			bot := &Bot{
 				Client:          mockMM,
 				TarantoolClient: mockTarantool,
-				UserID:          "test-user",
+				UserID:          "bot-user",
 			}
 
 			post := &model.Post{
@@ -160,6 +667,20 @@ func TestHandleVote(t *testing.T) {
 		Status:    "active",
 		CreatorID: "test-user",
 	}
+	approvalPoll := &tarantool.Poll{
+		PollID:  "approval-poll",
+		Options: []string{"A", "B", "C"},
+		Status:  "active",
+		Type:    tarantool.PollApproval,
+	}
+	scorePoll := &tarantool.Poll{
+		PollID:   "score-poll",
+		Options:  []string{"A", "B"},
+		Status:   "active",
+		Type:     tarantool.PollScore,
+		ScoreMin: 0,
+		ScoreMax: 5,
+	}
 
 	tests := []struct {
 		name        string
@@ -167,6 +688,46 @@ func TestHandleVote(t *testing.T) {
 		setupMocks  func()
 		expectError bool
 	}{
+		{
+			name: "approval vote",
+			args: []string{"approval-poll", "3", "1"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "approval-poll").Return(approvalPoll, nil)
+				mockTarantool.On("AddVote", context.Background(), "approval-poll", "voter-user", "1,3").Return(nil)
+				mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "score vote",
+			args: []string{"score-poll", "2=3", "1=5"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "score-poll").Return(scorePoll, nil)
+				mockTarantool.On("AddVote", context.Background(), "score-poll", "voter-user", "1=5,2=3").Return(nil)
+				mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "score out of scale",
+			args: []string{"score-poll", "1=6"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "score-poll").Return(scorePoll, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return strings.Contains(post.Message, "Неверные оценки")
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name: "several options in single choice poll",
+			args: []string{"test-poll", "1", "2"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return strings.Contains(post.Message, "Неверный номер варианта")
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
+		},
 		{
 			name: "valid vote",
 			args: []string{"test-poll", "1"},
@@ -176,6 +737,18 @@ func TestHandleVote(t *testing.T) {
 				mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil)
 			},
 		},
+		{
+			name: "locked vote",
+			args: []string{"test-poll", "2"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("AddVote", context.Background(), "test-poll", "voter-user", "2").Return(tarantool.ErrVoteLocked)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return strings.Contains(post.Message, "голос нельзя изменить")
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+			expectError: true,
+		},
 		{
 			name: "invalid poll",
 			args: []string{"invalid-poll", "1"},
@@ -216,6 +789,170 @@ func TestHandleVote(t *testing.T) {
 	}
 }
 
+func TestHandleResults(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		ChannelID: "test-channel",
+	}
+
+	tests := []struct {
+		name       string
+		results    *tarantool.VoteResult
+		weights    []tarantool.Weight
+		contains   []string
+		notContain []string
+	}{
+		{
+			name: "without quorum",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "active",
+				Options:  []string{"A", "B"},
+				Votes:    []int{2, 1},
+				Total:    3,
+			},
+			contains:   []string{"1. A - 2 голосов\n`███████░░░ 67%`", "2. B - 1 голосов\n`███░░░░░░░ 33%`", "Всего голосов: 3", "Победитель: A (отрыв: 1 голосов)"},
+			notContain: []string{"Кворум"},
+		},
+		{
+			name: "quorum reached",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "active",
+				Options:  []string{"A", "B"},
+				Votes:    []int{2, 1},
+				Total:    3,
+				Quorum:   3,
+				Eligible: 4,
+			},
+			contains: []string{"Явка: 3 из 4 (75%)", "Кворум: 3 — достигнут"},
+		},
+		{
+			name: "closed without quorum",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "closed",
+				Options:  []string{"A", "B"},
+				Votes:    []int{1, 0},
+				Total:    1,
+				Quorum:   3,
+				Eligible: 4,
+			},
+			contains:   []string{"Кворум: 3 — не достигнут", "кворум не достигнут, решение не принято"},
+			notContain: []string{"Победитель"},
+		},
+		{
+			name: "approval",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "active",
+				Type:     tarantool.PollApproval,
+				Options:  []string{"A", "B"},
+				Votes:    []int{2, 1},
+				Total:    2,
+			},
+			contains: []string{"1. A - 2 одобрений", "2. B - 1 одобрений", "Всего проголосовавших: 2", "`██████████ 100%`"},
+		},
+		{
+			name: "score",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "active",
+				Type:     tarantool.PollScore,
+				ScoreMax: 5,
+				Options:  []string{"A", "B"},
+				Votes:    []int{3, 0},
+				Total:    3,
+				Scores: []tarantool.ScoreStats{
+					{Count: 3, Average: 4, Median: 4, Distribution: map[int]int{5: 1, 4: 1, 3: 1}},
+					{Distribution: map[int]int{}},
+				},
+			},
+			contains:   []string{"1. A - средняя 4.00, медиана 4, оценок 3 (5: 1, 4: 1, 3: 1)", "2. B - нет оценок", "Победитель: A"},
+			notContain: []string{"отрыв"},
+		},
+		{
+			name: "with delegation",
+			results: &tarantool.VoteResult{
+				Question:  "Test question?",
+				Status:    "active",
+				Options:   []string{"A", "B"},
+				Votes:     []int{3, 1},
+				Total:     4,
+				Delegated: 2,
+			},
+			contains: []string{"Всего голосов: 4", "Из них по доверенности: 2"},
+		},
+		{
+			name: "weighted",
+			results: &tarantool.VoteResult{
+				Question: "Test question?",
+				Status:   "active",
+				Options:  []string{"A", "B"},
+				Ballots: []tarantool.Vote{
+					{PollID: "test-poll", UserID: "maintainer", Option: "1"},
+					{PollID: "test-poll", UserID: "user1", Option: "2"},
+					{PollID: "test-poll", UserID: "user2", Option: "2"},
+				},
+				Votes: []int{1, 2},
+				Total: 3,
+			},
+			weights:  []tarantool.Weight{{Scope: "test-poll", Subject: "user:maintainer", Value: 2}},
+			contains: []string{"1. A - 1 голосов (с учётом весов: 2)", "2. B - 2 голосов (с учётом весов: 2)", "Всего голосов: 3, с учётом весов: 4", "Ничья между A и B"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.results.Rank()
+			mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+			mockTarantool.On("GetResults", context.Background(), "test-poll").Return(tc.results, nil)
+			mockTarantool.On("GetWeights", context.Background(), "test-channel").Return([]tarantool.Weight{}, nil)
+			mockTarantool.On("GetWeights", context.Background(), "test-poll").Return(tc.weights, nil)
+			if tc.results.Type != tarantool.PollScore {
+				mockMM.On("UploadFile", context.Background(), mock.AnythingOfType("[]uint8"), "test-channel", "results-test-poll.png").Return(&model.FileUploadResponse{
+					FileInfos: []*model.FileInfo{{Id: "chart-id"}},
+				}, &model.Response{}, nil)
+			}
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				for _, s := range tc.contains {
+					if !strings.Contains(post.Message, s) {
+						return false
+					}
+				}
+				for _, s := range tc.notContain {
+					if strings.Contains(post.Message, s) {
+						return false
+					}
+				}
+				return true
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "test-user",
+				ChannelId: "test-channel",
+				Message:   "/results test-poll",
+			}
+
+			bot.handleResults(post, []string{"test-poll"})
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
 func TestHandleEndPoll(t *testing.T) {
 	mockTarantool := new(MockTarantool)
 	mockMM := new(MockMattermostClient)
@@ -238,9 +975,66 @@ func TestHandleEndPoll(t *testing.T) {
 			userID: "creator-user",
 			args:   []string{"test-poll"},
 			setupMocks: func() {
+				results := &tarantool.VoteResult{
+					Question: "Test question?",
+					Status:   "closed",
+					Options:  []string{"A", "B"},
+					Votes:    []int{1, 3},
+					Total:    4,
+				}
+				results.Rank()
 				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
-				mockTarantool.On("UpdatePollStatus", context.Background(), "test-poll", "closed").Return(nil)
-				mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil)
+				mockTarantool.On("ClosePoll", context.Background(), "test-poll").Return(true, nil)
+				mockTarantool.On("GetResults", context.Background(), "test-poll").Return(results, nil)
+				mockTarantool.On("GetWeights", context.Background(), "test-poll").Return([]tarantool.Weight{}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "test-channel" &&
+						strings.Contains(post.Message, "Голосование завершено!") &&
+						strings.Contains(post.Message, "Победитель: B (отрыв: 2 голосов)")
+				})).Return(&model.Post{Id: "results-post"}, &model.Response{}, nil)
+				mockTarantool.On("SaveFinalResult", context.Background(), mock.MatchedBy(func(result tarantool.FinalResult) bool {
+					return result.PollID == "test-poll" &&
+						result.PostID == "results-post" &&
+						strings.Contains(result.Summary, "Победитель: B") &&
+						result.ClosedAt > 0
+				})).Return(nil)
+				mockTarantool.On("GetVoters", context.Background(), "test-poll").Return([]string{"alice", "bob"}, nil)
+				mockMM.On("GetUsersByIds", context.Background(), []string{"alice", "bob"}).Return([]*model.User{
+					{Id: "alice", Username: "alice"}, {Id: "bob", Username: "bob"},
+				}, &model.Response{}, nil)
+				mockMM.On("CreateDirectChannel", context.Background(), "creator-user", "creator-user").Return(&model.Channel{Id: "dm-channel"}, &model.Response{}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "dm-channel" &&
+						strings.Contains(post.Message, "Участников: 2: @alice, @bob") &&
+						strings.Contains(post.Message, "/export test-poll csv")
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+		{
+			name:   "closed concurrently",
+			userID: "creator-user",
+			args:   []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("ClosePoll", context.Background(), "test-poll").Return(false, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.Message == "Голосование уже завершено"
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+		{
+			name:   "already closed",
+			userID: "creator-user",
+			args:   []string{"closed-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "closed-poll").Return(&tarantool.Poll{
+					PollID:    "closed-poll",
+					CreatorID: "creator-user",
+					Status:    "closed",
+				}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.Message == "Голосование уже завершено"
+				})).Return(&model.Post{}, &model.Response{}, nil)
 			},
 		},
 		{
@@ -353,3 +1147,19 @@ func TestHandleDeletePoll(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{`/vote poll 1`, []string{"/vote", "poll", "1"}},
+		{`/createpoll "Обед где?" "Кафе у офиса" Столовая`, []string{"/createpoll", "Обед где?", "Кафе у офиса", "Столовая"}},
+		{`/createpoll --type=text «Темы на пятницу?»`, []string{"/createpoll", "--type=text", "Темы на пятницу?"}},
+		{`/createpoll "" x`, []string{"/createpoll", "", "x"}},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, splitArgs(tc.message), tc.message)
+	}
+}
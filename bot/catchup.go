@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// catchUp выполняет команды, отправленные, пока бот был отключён: для каждого
+// канала бота запрашивает посты после последнего обработанного и ставит их
+// по порядку в очереди обработчиков, как события websocket. Повторы
+// отсекаются в handlePost по ID поста.
+func (b *Bot) catchUp(ctx context.Context) {
+	channels, err := b.memberChannels()
+	if err != nil {
+		b.logger().Error("Ошибка получения каналов для догонки", "err", err)
+		return
+	}
+
+	for _, channel := range channels {
+		// Догонка многих каналов не должна выглядеть для Alive зависанием
+		b.beat()
+		if err := b.catchUpChannel(ctx, channel); err != nil {
+			return
+		}
+	}
+}
+
+// catchUpChannel возвращает ошибку, только если постановка в очередь
+// прервана отменой ctx.
+func (b *Bot) catchUpChannel(ctx context.Context, channel *model.Channel) error {
+	cursor, err := b.TarantoolClient.GetChannelCursor(b.ctx(), channel.Id)
+	if err != nil {
+		b.logger().Error("Ошибка получения позиции канала", "channel_id", channel.Id, "err", err)
+		return nil
+	}
+
+	// Новый канал не разбираем задним числом, только запоминаем позицию
+	if cursor == 0 {
+		if err := b.TarantoolClient.AdvanceChannelCursor(b.ctx(), channel.Id, channel.LastPostAt); err != nil {
+			b.logger().Error("Ошибка сохранения позиции канала", "channel_id", channel.Id, "err", err)
+		}
+		return nil
+	}
+	if channel.LastPostAt <= cursor {
+		return nil
+	}
+
+	list, _, err := b.Client.GetPostsSince(b.ctx(), channel.Id, cursor, false)
+	if err != nil {
+		b.logger().Error("Ошибка получения пропущенных постов канала", "channel_id", channel.Id, "err", err)
+		return nil
+	}
+
+	// GetPostsSince возвращает и старые посты, изменённые после cursor
+	var missed []*model.Post
+	for _, post := range list.Posts {
+		if post.CreateAt > cursor && post.DeleteAt == 0 && !post.IsSystemMessage() {
+			missed = append(missed, post)
+		}
+	}
+	slices.SortFunc(missed, func(a, b *model.Post) int {
+		return cmp.Compare(a.CreateAt, b.CreateAt)
+	})
+
+	if len(missed) > 0 {
+		b.logger().Info("Догонка канала", "channel_id", channel.Id, "missed", len(missed))
+	}
+	for _, post := range missed {
+		if err := b.replayPost(ctx, post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayPost ставит пропущенный пост в очередь его автора, чтобы он
+// выполнился по порядку с его командами, пришедшими по websocket.
+func (b *Bot) replayPost(ctx context.Context, post *model.Post) error {
+	return b.pool.submit(ctx, post.UserId, func() {
+		defer b.logPanic("пропущенный пост "+post.Id, post.Message)
+		b.handlePost(post)
+	})
+}
+
+// memberChannels возвращает каналы всех команд, в которых состоит бот,
+// включая личные и групповые переписки.
+func (b *Bot) memberChannels() ([]*model.Channel, error) {
+	teams, _, err := b.Client.GetTeamsForUser(b.ctx(), b.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var channels []*model.Channel
+	for _, team := range teams {
+		teamChannels, _, err := b.Client.GetChannelsForTeamForUser(b.ctx(), team.Id, b.UserID, false, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, channel := range teamChannels {
+			// Личные переписки повторяются в каждой команде
+			if !seen[channel.Id] {
+				seen[channel.Id] = true
+				channels = append(channels, channel)
+			}
+		}
+	}
+	return channels, nil
+}
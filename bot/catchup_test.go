@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCatchUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   *model.Channel
+		cursor    int64
+		posts     []*model.Post
+		setupMock func(*MockTarantool, *MockMattermostClient)
+	}{
+		{
+			name:    "new channel",
+			channel: &model.Channel{Id: "new-channel", LastPostAt: 5000},
+			setupMock: func(mt *MockTarantool, mm *MockMattermostClient) {
+				// История канала не разбирается, запоминается только позиция
+				mt.On("AdvanceChannelCursor", context.Background(), "new-channel", int64(5000)).Return(nil)
+			},
+		},
+		{
+			name:    "no new posts",
+			channel: &model.Channel{Id: "test-channel", LastPostAt: 1000},
+			cursor:  1000,
+		},
+		{
+			name:    "missed commands",
+			channel: &model.Channel{Id: "test-channel", LastPostAt: 3000},
+			cursor:  1000,
+			posts: []*model.Post{
+				{Id: "second", ChannelId: "test-channel", UserId: "user", Message: "/vote", CreateAt: 3000},
+				{Id: "first", ChannelId: "test-channel", UserId: "user", Message: "/results", CreateAt: 2000},
+				{Id: "edited", ChannelId: "test-channel", UserId: "user", Message: "/endpoll", CreateAt: 500, UpdateAt: 2500},
+				{Id: "deleted", ChannelId: "test-channel", UserId: "user", Message: "/deletepoll", CreateAt: 2200, DeleteAt: 2300},
+				{Id: "chat", ChannelId: "test-channel", UserId: "user", Message: "привет", CreateAt: 2100},
+				{Id: "own", ChannelId: "test-channel", UserId: "bot-user", Message: "/results", CreateAt: 2400},
+			},
+			setupMock: func(mt *MockTarantool, mm *MockMattermostClient) {
+				mt.On("ClaimPost", context.Background(), "first", "test-channel", int64(2000)).Return(true, nil).Once()
+				mt.On("AdvanceChannelCursor", context.Background(), "test-channel", int64(2100)).Return(nil).Once()
+				mt.On("ClaimPost", context.Background(), "second", "test-channel", int64(3000)).Return(true, nil).Once()
+
+				mm.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.Message == "Использование: /results ID_ГОЛОСОВАНИЯ"
+				})).Return(&model.Post{}, &model.Response{}, nil).Once()
+				mm.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.Message != "Использование: /results ID_ГОЛОСОВАНИЯ"
+				})).Return(&model.Post{}, &model.Response{}, nil).Once()
+			},
+		},
+		{
+			name:    "already processed",
+			channel: &model.Channel{Id: "test-channel", LastPostAt: 2000},
+			cursor:  1000,
+			posts: []*model.Post{
+				{Id: "handled", ChannelId: "test-channel", UserId: "user", Message: "/results", CreateAt: 2000},
+			},
+			setupMock: func(mt *MockTarantool, mm *MockMattermostClient) {
+				// Команда уже выполнена по websocket, ответа нет
+				mt.On("ClaimPost", context.Background(), "handled", "test-channel", int64(2000)).Return(false, nil)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTarantool := new(MockTarantool)
+			mockMM := new(MockMattermostClient)
+
+			mockMM.On("GetTeamsForUser", context.Background(), "bot-user", "").Return([]*model.Team{{Id: "team1"}, {Id: "team2"}}, &model.Response{}, nil)
+			// Личная переписка есть в обеих командах, но догоняется один раз
+			mockMM.On("GetChannelsForTeamForUser", context.Background(), "team1", "bot-user", false, "").Return([]*model.Channel{tc.channel}, &model.Response{}, nil)
+			mockMM.On("GetChannelsForTeamForUser", context.Background(), "team2", "bot-user", false, "").Return([]*model.Channel{tc.channel}, &model.Response{}, nil)
+			mockTarantool.On("GetChannelCursor", context.Background(), tc.channel.Id).Return(tc.cursor, nil).Once()
+			if tc.posts != nil {
+				list := model.NewPostList()
+				for _, post := range tc.posts {
+					list.AddPost(post)
+					list.AddOrder(post.Id)
+				}
+				mockMM.On("GetPostsSince", context.Background(), tc.channel.Id, tc.cursor, false).Return(list, &model.Response{}, nil).Once()
+			}
+			if tc.setupMock != nil {
+				tc.setupMock(mockTarantool, mockMM)
+			}
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+				UserID:          "bot-user",
+				pool:            newWorkerPool(2, 1, slog.Default(), nil),
+			}
+
+			bot.catchUp(context.Background())
+			bot.pool.stop()
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+		})
+	}
+}
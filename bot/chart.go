@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const (
+	barCells = 10 // ширина текстовой полосы в символах
+
+	chartPadding  = 20
+	chartRow      = 40
+	chartBar      = 24
+	chartBarWidth = 400
+	chartScale    = 4 // пикселей на точку шрифта
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartTrack      = color.RGBA{0xe8, 0xea, 0xed, 0xff}
+	chartText       = color.RGBA{0x3d, 0x3c, 0x40, 0xff}
+	chartPalette    = []color.RGBA{
+		{0x1c, 0x58, 0xd9, 0xff},
+		{0x06, 0xd6, 0xa0, 0xff},
+		{0xff, 0xbc, 0x1f, 0xff},
+		{0xd2, 0x4b, 0x4e, 0xff},
+		{0x8a, 0x5c, 0xd6, 0xff},
+		{0x3d, 0xb8, 0xd9, 0xff},
+	}
+)
+
+// chartGlyphs — точечный шрифт 3×5 для номеров вариантов и процентов.
+var chartGlyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+}
+
+// resultShares возвращает долю каждого варианта от 0 до 1.
+// nil — для голосования диаграмма не строится.
+func resultShares(results *tarantool.VoteResult) []float64 {
+	if results.Percentages == nil || (results.Type == tarantool.PollQuiz && results.Status == "active") {
+		return nil
+	}
+
+	shares := make([]float64, len(results.Percentages))
+	for i, percent := range results.Percentages {
+		shares[i] = percent / 100
+	}
+	return shares
+}
+
+// resultBar рисует долю полосой из символов: ██████░░░░ 62%.
+func resultBar(share float64) string {
+	filled := int(math.Round(share * barCells))
+	return fmt.Sprintf("`%s%s %d%%`",
+		strings.Repeat("█", filled),
+		strings.Repeat("░", barCells-filled),
+		int(math.Round(share*100)))
+}
+
+// renderChart рисует PNG-диаграмму: по полосе на вариант с номером слева
+// и процентом справа. Названия вариантов остаются в тексте сообщения.
+func renderChart(shares []float64) ([]byte, error) {
+	labelWidth := glyphsWidth(strconv.Itoa(len(shares)))
+	barX := chartPadding + labelWidth + chartPadding
+	width := barX + chartBarWidth + chartPadding + glyphsWidth("100%") + chartPadding
+	height := 2*chartPadding + len(shares)*chartRow - (chartRow - chartBar)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	textY := (chartBar - 5*chartScale) / 2
+	for i, share := range shares {
+		y := chartPadding + i*chartRow
+
+		label := strconv.Itoa(i + 1)
+		drawGlyphs(img, chartPadding+labelWidth-glyphsWidth(label), y+textY, label)
+
+		track := image.Rect(barX, y, barX+chartBarWidth, y+chartBar)
+		draw.Draw(img, track, &image.Uniform{chartTrack}, image.Point{}, draw.Src)
+		bar := image.Rect(barX, y, barX+int(math.Round(share*chartBarWidth)), y+chartBar)
+		draw.Draw(img, bar, &image.Uniform{chartPalette[i%len(chartPalette)]}, image.Point{}, draw.Src)
+
+		percent := strconv.Itoa(int(math.Round(share*100))) + "%"
+		drawGlyphs(img, barX+chartBarWidth+chartPadding, y+textY, percent)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func glyphsWidth(text string) int {
+	n := len([]rune(text))
+	return n*4*chartScale - chartScale
+}
+
+func drawGlyphs(img *image.RGBA, x, y int, text string) {
+	for _, r := range text {
+		for row, line := range chartGlyphs[r] {
+			for col, dot := range line {
+				if dot != '#' {
+					continue
+				}
+				px := image.Rect(x+col*chartScale, y+row*chartScale, x+(col+1)*chartScale, y+(row+1)*chartScale)
+				draw.Draw(img, px, &image.Uniform{chartText}, image.Point{}, draw.Src)
+			}
+		}
+		x += 4 * chartScale
+	}
+}
+
+// sendResults публикует результаты с диаграммой во вложении. Если
+// диаграмму построить или загрузить не удалось, уходит только текст.
+func (b *Bot) sendResults(channelId string, pollID string, results *tarantool.VoteResult) {
+	response := formatResults(results)
+
+	shares := resultShares(results)
+	if shares == nil {
+		b.sendReply(channelId, response)
+		return
+	}
+
+	chart, err := renderChart(shares)
+	if err != nil {
+		b.logger().Error("Ошибка построения диаграммы", "err", err)
+		b.sendReply(channelId, response)
+		return
+	}
+
+	upload, _, err := b.Client.UploadFile(b.ctx(), chart, channelId, "results-"+pollID+".png")
+	if err != nil || len(upload.FileInfos) == 0 {
+		b.logger().Error("Ошибка загрузки диаграммы", "err", err)
+		b.sendReply(channelId, response)
+		return
+	}
+
+	_, _, err = b.Client.CreatePost(b.ctx(), &model.Post{
+		ChannelId: channelId,
+		Message:   response,
+		FileIds:   model.StringArray{upload.FileInfos[0].Id},
+	})
+	if err != nil {
+		b.logger().Error("Ошибка отправки сообщения", "err", err)
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"voting-bot/tarantool"
+)
+
+func TestResultBar(t *testing.T) {
+	assert.Equal(t, "`░░░░░░░░░░ 0%`", resultBar(0))
+	assert.Equal(t, "`██████░░░░ 62%`", resultBar(0.62))
+	assert.Equal(t, "`██████████ 100%`", resultBar(1))
+}
+
+func TestResultShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		results *tarantool.VoteResult
+		want    []float64
+	}{
+		{
+			name:    "votes",
+			results: &tarantool.VoteResult{Options: []string{"A", "B"}, Votes: []int{3, 1}, Total: 4},
+			want:    []float64{0.75, 0.25},
+		},
+		{
+			name: "weighted",
+			results: &tarantool.VoteResult{
+				Options:       []string{"A", "B"},
+				Votes:         []int{1, 1},
+				Total:         2,
+				Weighted:      []float64{3, 1},
+				WeightedTotal: 4,
+			},
+			want: []float64{0.75, 0.25},
+		},
+		{
+			name:    "no votes",
+			results: &tarantool.VoteResult{Options: []string{"A", "B"}, Votes: []int{0, 0}},
+			want:    []float64{0, 0},
+		},
+		{
+			name:    "score",
+			results: &tarantool.VoteResult{Type: tarantool.PollScore, Options: []string{"A"}, Votes: []int{2}, Total: 2},
+		},
+		{
+			name:    "active quiz",
+			results: &tarantool.VoteResult{Type: tarantool.PollQuiz, Status: "active", Options: []string{"A"}, Votes: []int{1}, Total: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.results.Rank()
+			assert.Equal(t, tc.want, resultShares(tc.results))
+		})
+	}
+}
+
+func TestRenderChart(t *testing.T) {
+	data, err := renderChart([]float64{0.5, 0.25, 0.25})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	bounds := img.Bounds()
+	assert.Equal(t, 2*chartPadding+3*chartRow-(chartRow-chartBar), bounds.Dy())
+
+	// Первая полоса закрашена на половину ширины
+	barX := chartPadding + glyphsWidth("3") + chartPadding
+	y := chartPadding + chartBar/2
+	assert.Equal(t, chartPalette[0], img.At(barX+chartBarWidth/2-1, y))
+	assert.Equal(t, chartTrack, img.At(barX+chartBarWidth/2+1, y))
+}
+
+func TestSendResultsFallback(t *testing.T) {
+	mockMM := new(MockMattermostClient)
+
+	results := &tarantool.VoteResult{
+		Question: "Test question?",
+		Options:  []string{"A", "B"},
+		Votes:    []int{1, 1},
+		Total:    2,
+	}
+	results.Rank()
+
+	mockMM.On("UploadFile", context.Background(), mock.AnythingOfType("[]uint8"), "test-channel", "results-test-poll.png").
+		Return(nil, &model.Response{}, errors.New("upload failed"))
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return len(post.FileIds) == 0 && strings.Contains(post.Message, "1. A - 1 голосов\n`█████░░░░░ 50%`")
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{Client: mockMM}
+	bot.sendResults("test-channel", "test-poll", results)
+
+	mockMM.AssertExpectations(t)
+}
@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	defaultReconnectDelay       = time.Second
+	defaultMaxReconnectDelay    = time.Minute
+	DefaultMaxReconnectAttempts = 10
+
+	// stableConnection — сколько соединение должно продержаться, чтобы его
+	// обрыв не считался неудачной попыткой подключения.
+	stableConnection = time.Minute
+)
+
+var (
+	errConnectionClosed = errors.New("websocket connection closed")
+	errPingTimeout      = errors.New("websocket ping timeout")
+)
+
+// eventStream — открытое соединение, из которого бот читает события.
+type eventStream interface {
+	Events() <-chan *model.WebSocketEvent
+	PingTimeouts() <-chan bool
+	Err() error // причина обрыва после закрытия Events
+	Close()
+}
+
+// wsStream читает события из websocket-клиента Mattermost.
+type wsStream struct {
+	ws *model.WebSocketClient
+}
+
+func (s wsStream) Events() <-chan *model.WebSocketEvent { return s.ws.EventChannel }
+func (s wsStream) PingTimeouts() <-chan bool            { return s.ws.PingTimeoutChannel }
+func (s wsStream) Close()                               { s.ws.Close() }
+
+func (s wsStream) Err() error {
+	if s.ws.ListenError != nil {
+		return s.ws.ListenError
+	}
+	return nil
+}
+
+// dialWebSocket открывает websocket-соединение и запускает чтение событий.
+func dialWebSocket(serverURL, token string) (eventStream, error) {
+	ws, err := model.NewWebSocketClient4(serverURL, token)
+	if err != nil {
+		return nil, err
+	}
+	ws.Listen()
+	return wsStream{ws: ws}, nil
+}
+
+// ConnectionState — состояние соединения с Mattermost для проверок живости.
+type ConnectionState struct {
+	Connected bool
+	Since     time.Time // момент последней смены состояния
+	Failures  int       // неудачных попыток переподключения подряд
+	LastError string
+}
+
+type connection struct {
+	mu     sync.Mutex
+	state  ConnectionState
+	stream eventStream
+}
+
+func (c *connection) set(connected bool, failures int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state.Connected != connected || c.state.Since.IsZero() {
+		c.state.Since = time.Now()
+	}
+	c.state.Connected = connected
+	c.state.Failures = failures
+	if err != nil {
+		c.state.LastError = err.Error()
+	}
+}
+
+func (c *connection) setStream(stream eventStream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stream = stream
+}
+
+// closeStream закрывает текущее соединение, если оно открыто.
+func (c *connection) closeStream() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stream != nil {
+		c.stream.Close()
+		c.stream = nil
+	}
+}
+
+func (c *connection) get() ConnectionState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// ConnectionState возвращает текущее состояние соединения с Mattermost.
+func (b *Bot) ConnectionState() ConnectionState {
+	return b.conn.get()
+}
+
+// run поддерживает соединение с Mattermost, пока не отменён ctx. При обрыве
+// (ошибка чтения, закрытый канал событий, пропущенные ping) бот заново
+// проверяет токен и переподключается с экспоненциальной задержкой, а после
+// подключения выполняет команды, пропущенные за время обрыва. Обрыв раньше
+// stableConnection считается неудачной попыткой, чтобы соединение, которое
+// рвётся сразу после подключения, не нагружало Mattermost и Tarantool
+// переподключениями и догонкой без паузы.
+// Возвращает ошибку, если MaxReconnectAttempts попыток подряд не удались,
+// чтобы оркестратор перезапустил процесс.
+func (b *Bot) run(ctx context.Context) error {
+	failures := 0
+	for ctx.Err() == nil {
+		stream, err := b.connect()
+		if err != nil {
+			failures++
+			b.conn.set(false, failures, err)
+			if b.MaxReconnectAttempts > 0 && failures >= b.MaxReconnectAttempts {
+				return fmt.Errorf("не удалось подключиться к Mattermost после %d попыток: %w", failures, err)
+			}
+
+			delay := b.reconnectDelay(failures)
+			b.logger().Warn("Ошибка подключения к Mattermost", "err", err, "retry_in", delay)
+			b.waitReconnect(ctx, delay)
+			continue
+		}
+
+		b.conn.setStream(stream)
+		b.conn.set(true, 0, nil)
+		b.logger().Info("Подключение к Mattermost установлено")
+		b.catchUp(ctx)
+
+		connectedAt := time.Now()
+		err = b.consume(ctx, stream)
+		if ctx.Err() != nil {
+			// Соединение закроет Listen, когда завершатся начатые команды
+			return nil
+		}
+		b.conn.closeStream()
+		b.metrics.Reconnected()
+
+		if time.Since(connectedAt) >= stableConnection {
+			failures = 0
+		}
+		failures++
+		b.conn.set(false, failures, err)
+		if b.MaxReconnectAttempts > 0 && failures >= b.MaxReconnectAttempts {
+			return fmt.Errorf("соединение с Mattermost обрывалось %d раз подряд: %w", failures, err)
+		}
+
+		delay := b.reconnectDelay(failures)
+		b.logger().Error("Соединение с Mattermost потеряно", "err", err, "retry_in", delay)
+		b.waitReconnect(ctx, delay)
+	}
+	return nil
+}
+
+// waitReconnect ждёт delay перед переподключением или отмены ctx. Пауза
+// отмечается для Alive, поэтому не считается зависанием.
+func (b *Bot) waitReconnect(ctx context.Context, delay time.Duration) {
+	b.beat()
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
+}
+
+// connect заново авторизуется через REST API и открывает поток событий.
+func (b *Bot) connect() (eventStream, error) {
+	if _, _, err := b.Client.GetMe(b.ctx(), ""); err != nil {
+		return nil, err
+	}
+	return b.dial()
+}
+
+// consume обрабатывает события, пока соединение живо и ctx не отменён,
+// и возвращает причину остановки.
+func (b *Bot) consume(ctx context.Context, stream eventStream) error {
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		b.beat()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-stream.Events():
+			if !ok {
+				if err := stream.Err(); err != nil {
+					return err
+				}
+				return errConnectionClosed
+			}
+			if err := b.dispatch(ctx, event); err != nil {
+				return err
+			}
+		case <-stream.PingTimeouts():
+			return errPingTimeout
+		case <-heartbeat.C:
+		}
+	}
+}
+
+// reconnectDelay удваивает задержку с каждой неудачей до MaxReconnectDelay
+// и случайно уменьшает её до половины, чтобы реплики не переподключались разом.
+func (b *Bot) reconnectDelay(failures int) time.Duration {
+	delay := b.ReconnectDelay
+	for i := 1; i < failures && delay < b.MaxReconnectDelay; i++ {
+		delay *= 2
+	}
+	if delay > b.MaxReconnectDelay {
+		delay = b.MaxReconnectDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
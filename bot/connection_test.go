@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeStream struct {
+	events chan *model.WebSocketEvent
+	pings  chan bool
+	err    error
+	closed atomic.Bool
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{
+		events: make(chan *model.WebSocketEvent, 1),
+		pings:  make(chan bool, 1),
+	}
+}
+
+func (s *fakeStream) Events() <-chan *model.WebSocketEvent { return s.events }
+func (s *fakeStream) PingTimeouts() <-chan bool            { return s.pings }
+func (s *fakeStream) Err() error                           { return s.err }
+func (s *fakeStream) Close()                               { s.closed.Store(true) }
+
+func TestListenReconnects(t *testing.T) {
+	mockMM := new(MockMattermostClient)
+	mockMM.On("GetMe", mock.Anything, "").Return(&model.User{Id: "bot-user"}, &model.Response{}, nil)
+	mockMM.On("GetTeamsForUser", mock.Anything, "bot-user", "").Return([]*model.Team{}, &model.Response{}, nil)
+
+	// Первое соединение обрывается с ошибкой, второе — по пропущенным ping,
+	// затем сервер недоступен. Обрывы сразу после подключения считаются
+	// неудачными попытками
+	dropped := newFakeStream()
+	dropped.events <- model.NewWebSocketEvent(model.WebsocketEventTyping, "", "", "", nil, "")
+	close(dropped.events)
+	dropped.err = errors.New("read: connection reset")
+
+	silent := newFakeStream()
+	silent.pings <- true
+
+	streams := []*fakeStream{dropped, silent}
+	dials := 0
+	bot := &Bot{
+		Client:               mockMM,
+		UserID:               "bot-user",
+		MaxReconnectAttempts: 3,
+		ShutdownTimeout:      time.Second,
+		dial: func() (eventStream, error) {
+			dials++
+			if len(streams) == 0 {
+				return nil, errors.New("connection refused")
+			}
+			stream := streams[0]
+			streams = streams[1:]
+			return stream, nil
+		},
+	}
+
+	err := bot.Listen(context.Background())
+
+	assert.ErrorContains(t, err, "после 3 попыток")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, 3, dials)
+	assert.True(t, dropped.closed.Load())
+	assert.True(t, silent.closed.Load())
+
+	state := bot.ConnectionState()
+	assert.False(t, state.Connected)
+	assert.Equal(t, 3, state.Failures)
+	assert.Equal(t, "connection refused", state.LastError)
+	assert.False(t, state.Since.IsZero())
+	mockMM.AssertNumberOfCalls(t, "GetMe", 3)
+	// Догонка после каждого успешного подключения
+	mockMM.AssertNumberOfCalls(t, "GetTeamsForUser", 2)
+}
+
+func TestListenWaitsAfterDrop(t *testing.T) {
+	mockMM := new(MockMattermostClient)
+	mockMM.On("GetMe", mock.Anything, "").Return(&model.User{Id: "bot-user"}, &model.Response{}, nil)
+	mockMM.On("GetTeamsForUser", mock.Anything, "bot-user", "").Return([]*model.Team{}, &model.Response{}, nil)
+
+	const delay = 100 * time.Millisecond
+	var dialed []time.Time
+	bot := &Bot{
+		Client:               mockMM,
+		UserID:               "bot-user",
+		ReconnectDelay:       delay,
+		MaxReconnectDelay:    delay,
+		MaxReconnectAttempts: 3,
+		ShutdownTimeout:      time.Second,
+		dial: func() (eventStream, error) {
+			dialed = append(dialed, time.Now())
+			// Соединение закрывается сразу после подключения
+			stream := newFakeStream()
+			close(stream.events)
+			return stream, nil
+		},
+	}
+
+	err := bot.Listen(context.Background())
+
+	assert.ErrorContains(t, err, "обрывалось 3 раз подряд")
+	assert.Len(t, dialed, 3)
+	for i := 1; i < len(dialed); i++ {
+		assert.GreaterOrEqual(t, dialed[i].Sub(dialed[i-1]), delay/2)
+	}
+	assert.Equal(t, 3, bot.ConnectionState().Failures)
+}
+
+func TestListenReauthFails(t *testing.T) {
+	mockMM := new(MockMattermostClient)
+	mockMM.On("GetMe", mock.Anything, "").Return((*model.User)(nil), &model.Response{}, errors.New("invalid token"))
+
+	bot := &Bot{
+		Client:               mockMM,
+		MaxReconnectAttempts: 2,
+		ShutdownTimeout:      time.Second,
+		dial: func() (eventStream, error) {
+			t.Fatal("dial without authentication")
+			return nil, nil
+		},
+	}
+
+	err := bot.Listen(context.Background())
+
+	assert.ErrorContains(t, err, "invalid token")
+	assert.Equal(t, "invalid token", bot.ConnectionState().LastError)
+	mockMM.AssertNumberOfCalls(t, "GetMe", 2)
+}
+
+func TestConsume(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(s *fakeStream)
+		wantErr error
+	}{
+		{
+			name:    "closed channel",
+			prepare: func(s *fakeStream) { close(s.events) },
+			wantErr: errConnectionClosed,
+		},
+		{
+			name:    "ping timeout",
+			prepare: func(s *fakeStream) { s.pings <- true },
+			wantErr: errPingTimeout,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := newFakeStream()
+			tc.prepare(stream)
+
+			err := (&Bot{}).consume(context.Background(), stream)
+
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	bot := &Bot{
+		ReconnectDelay:    time.Second,
+		MaxReconnectDelay: 10 * time.Second,
+	}
+
+	tests := []struct {
+		failures int
+		max      time.Duration
+	}{
+		{failures: 1, max: time.Second},
+		{failures: 2, max: 2 * time.Second},
+		{failures: 3, max: 4 * time.Second},
+		{failures: 5, max: 10 * time.Second},
+		{failures: 50, max: 10 * time.Second},
+	}
+
+	for _, tc := range tests {
+		for range 20 {
+			delay := bot.reconnectDelay(tc.failures)
+			assert.GreaterOrEqual(t, delay, tc.max/2)
+			assert.LessOrEqual(t, delay, tc.max)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		valid bool
+	}{
+		{"every minute", "* * * * *", true},
+		{"weekday names", "0 10 * * MON-FRI", true},
+		{"lists and steps", "0,30 */2 1-15/3 JAN,JUL 0", true},
+		{"sunday as seven", "0 9 * * 7", true},
+		{"too few fields", "0 10 * *", false},
+		{"out of range", "60 10 * * *", false},
+		{"reversed range", "0 10 * * FRI-MON", false},
+		{"zero step", "*/0 * * * *", false},
+		{"garbage", "0 10 * * someday", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseCron(tc.expr)
+			assert.Equal(t, tc.valid, err == nil)
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	// 2026-10-18 — воскресенье
+	after := time.Date(2026, 10, 18, 12, 34, 56, 0, moscow)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, 10, 18, 12, 35, 0, 0, moscow)},
+		{"monday morning", "0 10 * * MON", time.Date(2026, 10, 19, 10, 0, 0, 0, moscow)},
+		{"later today", "30 18 * * *", time.Date(2026, 10, 18, 18, 30, 0, 0, moscow)},
+		{"sunday as seven", "0 9 * * 7", time.Date(2026, 10, 25, 9, 0, 0, 0, moscow)},
+		{"first of month", "0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, moscow)},
+		{"day of month or weekday", "0 12 1 * WED", time.Date(2026, 10, 21, 12, 0, 0, 0, moscow)},
+		{"leap day", "0 0 29 FEB *", time.Date(2028, 2, 29, 0, 0, 0, 0, moscow)},
+		{"never", "0 0 31 FEB *", time.Time{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := parseCron(tc.expr)
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(schedule.next(after)), "got %v", schedule.next(after))
+		})
+	}
+}
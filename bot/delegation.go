@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+func (b *Bot) handleDelegate(post *model.Post, args []string) {
+	if len(args) != 2 {
+		b.sendReply(post.ChannelId, "Использование: /delegate ID_ГОЛОСОВАНИЯ|channel @пользователь")
+		return
+	}
+
+	scopeID, ok := b.delegationScope(post, args[0])
+	if !ok {
+		return
+	}
+
+	username := strings.TrimPrefix(args[1], "@")
+	user, _, err := b.Client.GetUserByUsername(b.ctx(), username, "")
+	if err != nil || user == nil {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Пользователь @%s не найден", username))
+		return
+	}
+
+	if user.Id == post.UserId {
+		b.sendReply(post.ChannelId, "Нельзя передать голос самому себе")
+		return
+	}
+
+	err = b.TarantoolClient.SetDelegation(b.ctx(), tarantool.Delegation{
+		Scope:    scopeID,
+		FromUser: post.UserId,
+		ToUser:   user.Id,
+	})
+	if err != nil {
+		b.postLog(post).Error("Ошибка сохранения доверенности", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось передать голос")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Ваш голос передан @%s. Проголосовав сами, вы отмените передачу", user.Username))
+}
+
+func (b *Bot) handleUndelegate(post *model.Post, args []string) {
+	if len(args) != 1 {
+		b.sendReply(post.ChannelId, "Использование: /undelegate ID_ГОЛОСОВАНИЯ|channel")
+		return
+	}
+
+	scopeID, ok := b.delegationScope(post, args[0])
+	if !ok {
+		return
+	}
+
+	err := b.TarantoolClient.DeleteDelegation(b.ctx(), scopeID, post.UserId)
+	if errors.Is(err, tarantool.ErrNotFound) {
+		b.sendReply(post.ChannelId, "Вы не передавали голос")
+		return
+	}
+	if err != nil {
+		b.postLog(post).Error("Ошибка удаления доверенности", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось отменить передачу голоса")
+		return
+	}
+
+	b.sendReply(post.ChannelId, "Передача голоса отменена")
+}
+
+// delegationScope возвращает ID голосования или текущего канала для "channel".
+func (b *Bot) delegationScope(post *model.Post, scope string) (string, bool) {
+	if scope == "channel" {
+		return post.ChannelId, true
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), scope)
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return "", false
+	}
+
+	if poll.Status != "active" {
+		b.sendReply(post.ChannelId, "Голосование уже завершено")
+		return "", false
+	}
+	return poll.PollID, true
+}
@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleDelegate(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID: "test-poll",
+		Status: "active",
+	}
+	closedPoll := &tarantool.Poll{
+		PollID: "closed-poll",
+		Status: "closed",
+	}
+
+	tests := []struct {
+		name       string
+		command    string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:    "delegate in poll",
+			command: "/delegate",
+			args:    []string{"test-poll", "@colleague"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockMM.On("GetUserByUsername", context.Background(), "colleague", "").Return(&model.User{Id: "colleague-id", Username: "colleague"}, &model.Response{}, nil)
+				mockTarantool.On("SetDelegation", context.Background(), tarantool.Delegation{
+					Scope:    "test-poll",
+					FromUser: "voter-user",
+					ToUser:   "colleague-id",
+				}).Return(nil)
+			},
+			reply: "Ваш голос передан @colleague",
+		},
+		{
+			name:    "delegate in channel",
+			command: "/delegate",
+			args:    []string{"channel", "colleague"},
+			setupMocks: func() {
+				mockMM.On("GetUserByUsername", context.Background(), "colleague", "").Return(&model.User{Id: "colleague-id", Username: "colleague"}, &model.Response{}, nil)
+				mockTarantool.On("SetDelegation", context.Background(), tarantool.Delegation{
+					Scope:    "test-channel",
+					FromUser: "voter-user",
+					ToUser:   "colleague-id",
+				}).Return(nil)
+			},
+			reply: "Ваш голос передан @colleague",
+		},
+		{
+			name:    "delegate to self",
+			command: "/delegate",
+			args:    []string{"channel", "@me"},
+			setupMocks: func() {
+				mockMM.On("GetUserByUsername", context.Background(), "me", "").Return(&model.User{Id: "voter-user", Username: "me"}, &model.Response{}, nil)
+			},
+			reply: "Нельзя передать голос самому себе",
+		},
+		{
+			name:    "closed poll",
+			command: "/delegate",
+			args:    []string{"closed-poll", "@colleague"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "closed-poll").Return(closedPoll, nil)
+			},
+			reply: "Голосование уже завершено",
+		},
+		{
+			name:    "undelegate",
+			command: "/undelegate",
+			args:    []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("DeleteDelegation", context.Background(), "test-poll", "voter-user").Return(nil)
+			},
+			reply: "Передача голоса отменена",
+		},
+		{
+			name:    "undelegate without delegation",
+			command: "/undelegate",
+			args:    []string{"channel"},
+			setupMocks: func() {
+				mockTarantool.On("DeleteDelegation", context.Background(), "test-channel", "voter-user").Return(tarantool.ErrNotFound)
+			},
+			reply: "Вы не передавали голос",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "voter-user",
+				ChannelId: "test-channel",
+				Message:   tc.command + " " + strings.Join(tc.args, " "),
+			}
+
+			if tc.command == "/delegate" {
+				bot.handleDelegate(post, tc.args)
+			} else {
+				bot.handleUndelegate(post, tc.args)
+			}
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
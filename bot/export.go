@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+// pollExport — выгрузка итогов голосования для таблиц.
+type pollExport struct {
+	PollID   string         `json:"poll_id"`
+	Question string         `json:"question"`
+	Type     string         `json:"type"`
+	Status   string         `json:"status"`
+	Total    int            `json:"total"`
+	Options  []exportOption `json:"options"`
+	Votes    []exportVote   `json:"votes,omitempty"`   // пусто для анонимных голосований
+	Answers  []exportAnswer `json:"answers,omitempty"` // только для PollText
+}
+
+type exportOption struct {
+	Number  int     `json:"number"`
+	Text    string  `json:"text"`
+	Votes   int     `json:"votes"`
+	Percent float64 `json:"percent"`
+}
+
+// exportVote — выбор участника; бюллетень с несколькими вариантами
+// даёт по строке на каждый вариант.
+type exportVote struct {
+	Username  string `json:"username"`
+	Option    int    `json:"option"`
+	Text      string `json:"text"`
+	Value     int    `json:"value"` // оценка или доступность, для обычного выбора — 1
+	VotedAt   string `json:"voted_at,omitempty"`
+	Delegated bool   `json:"delegated"`
+}
+
+type exportAnswer struct {
+	Username   string `json:"username,omitempty"`
+	Text       string `json:"text"`
+	AnsweredAt string `json:"answered_at"`
+}
+
+func (b *Bot) handleExport(post *model.Post, args []string) {
+	if len(args) != 2 || (args[1] != "csv" && args[1] != "json") {
+		b.sendReply(post.ChannelId, "Использование: /export ID_ГОЛОСОВАНИЯ csv|json")
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	// Выгрузка раскрывает голоса по участникам, поэтому доступна только
+	// участникам канала голосования, даже если файл попадёт в другой канал
+	if !b.memberOfPollChannel(poll, post.UserId) {
+		b.sendReply(post.ChannelId, "Выгрузить можно только голосование из канала, участником которого вы являетесь")
+		return
+	}
+
+	if poll.Type == tarantool.PollQuiz && poll.Status == "active" {
+		b.sendReply(post.ChannelId, "Результаты викторины откроются после завершения")
+		return
+	}
+
+	export, err := b.buildExport(poll)
+	if err != nil {
+		b.postLog(post).Error("Ошибка выгрузки результатов", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось выгрузить результаты")
+		return
+	}
+
+	var data []byte
+	if args[1] == "csv" {
+		data, err = export.csv()
+	} else {
+		data, err = json.MarshalIndent(export, "", "  ")
+	}
+	if err != nil {
+		b.postLog(post).Error("Ошибка формирования файла", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось выгрузить результаты")
+		return
+	}
+
+	filename := fmt.Sprintf("poll-%s.%s", poll.PollID, args[1])
+	upload, _, err := b.Client.UploadFile(b.ctx(), data, post.ChannelId, filename)
+	if err != nil || len(upload.FileInfos) == 0 {
+		b.postLog(post).Error("Ошибка загрузки файла", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось загрузить файл с результатами")
+		return
+	}
+
+	_, _, err = b.Client.CreatePost(b.ctx(), &model.Post{
+		ChannelId: post.ChannelId,
+		Message:   fmt.Sprintf("Результаты голосования «%s»", poll.Question),
+		FileIds:   model.StringArray{upload.FileInfos[0].Id},
+	})
+	if err != nil {
+		b.postLog(post).Error("Ошибка отправки сообщения", "err", err)
+	}
+}
+
+// buildExport собирает итоги по вариантам и, если голосование не анонимное,
+// выбор каждого участника.
+func (b *Bot) buildExport(poll *tarantool.Poll) (*pollExport, error) {
+	export := &pollExport{
+		PollID:   poll.PollID,
+		Question: poll.Question,
+		Type:     poll.Type,
+		Status:   poll.Status,
+	}
+
+	if poll.Type == tarantool.PollText {
+		return export, b.exportAnswers(poll, export)
+	}
+
+	results, err := b.TarantoolClient.GetResults(b.ctx(), poll.PollID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Total = results.Total
+	for i, option := range results.Options {
+		row := exportOption{Number: i + 1, Text: option, Votes: results.Votes[i]}
+		if results.Percentages != nil {
+			row.Percent = math.Round(results.Percentages[i]*100) / 100
+		}
+		export.Options = append(export.Options, row)
+	}
+
+	if poll.Anonymous || len(results.Ballots) == 0 {
+		return export, nil
+	}
+
+	userIDs := make([]string, len(results.Ballots))
+	for i, vote := range results.Ballots {
+		userIDs[i] = vote.UserID
+	}
+	usernames, err := b.usernames(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vote := range results.Ballots {
+		ballot, err := poll.DecodeBallot(vote.Option)
+		if err != nil {
+			continue
+		}
+
+		options := make([]int, 0, len(ballot))
+		for n := range ballot {
+			options = append(options, n)
+		}
+		sort.Ints(options)
+
+		for _, n := range options {
+			export.Votes = append(export.Votes, exportVote{
+				Username:  usernames[vote.UserID],
+				Option:    n,
+				Text:      poll.Options[n-1],
+				Value:     ballot[n],
+				VotedAt:   exportTime(vote.VotedAt),
+				Delegated: vote.Delegated,
+			})
+		}
+	}
+	return export, nil
+}
+
+func (b *Bot) exportAnswers(poll *tarantool.Poll, export *pollExport) error {
+	answers, err := b.TarantoolClient.GetAnswers(b.ctx(), poll.PollID)
+	if err != nil {
+		return err
+	}
+	export.Total = len(answers)
+
+	usernames := make(map[string]string)
+	if !poll.Anonymous && len(answers) > 0 {
+		userIDs := make([]string, len(answers))
+		for i, answer := range answers {
+			userIDs[i] = answer.UserID
+		}
+		if usernames, err = b.usernames(userIDs); err != nil {
+			return err
+		}
+	}
+
+	for _, answer := range answers {
+		export.Answers = append(export.Answers, exportAnswer{
+			Username:   usernames[answer.UserID],
+			Text:       answer.Text,
+			AnsweredAt: exportTime(answer.CreatedAt),
+		})
+	}
+	return nil
+}
+
+// usernames возвращает имена пользователей по их ID.
+func (b *Bot) usernames(userIDs []string) (map[string]string, error) {
+	users, _, err := b.Client.GetUsersByIds(b.ctx(), userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		usernames[user.Id] = user.Username
+	}
+	return usernames, nil
+}
+
+// csv раскладывает выгрузку в таблицу: итоги по вариантам, затем через
+// пустую строку выбор участников или свободные ответы.
+func (e *pollExport) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if e.Type == tarantool.PollText {
+		w.Write([]string{"username", "text", "answered_at"})
+		for _, answer := range e.Answers {
+			w.Write([]string{answer.Username, answer.Text, answer.AnsweredAt})
+		}
+	} else {
+		w.Write([]string{"option", "text", "votes", "percent"})
+		for _, option := range e.Options {
+			w.Write([]string{
+				strconv.Itoa(option.Number),
+				option.Text,
+				strconv.Itoa(option.Votes),
+				strconv.FormatFloat(option.Percent, 'f', 2, 64),
+			})
+		}
+
+		if len(e.Votes) > 0 {
+			w.Write(nil)
+			w.Write([]string{"username", "option", "text", "value", "voted_at", "delegated"})
+			for _, vote := range e.Votes {
+				w.Write([]string{
+					vote.Username,
+					strconv.Itoa(vote.Option),
+					vote.Text,
+					strconv.Itoa(vote.Value),
+					vote.VotedAt,
+					strconv.FormatBool(vote.Delegated),
+				})
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// exportTime выводит unix-время в RFC 3339, 0 — время неизвестно.
+func exportTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleExport(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	votedAt := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC).Unix()
+	approval := &tarantool.Poll{
+		PollID:    "approval-poll",
+		ChannelID: "poll-channel",
+		Question:  "Что заказать?",
+		Options:   []string{"Пицца", "Суши"},
+		Status:    "closed",
+		Type:      tarantool.PollApproval,
+	}
+	anonymous := &tarantool.Poll{
+		PollID:    "anonymous-poll",
+		ChannelID: "poll-channel",
+		Question:  "Оцените спринт",
+		Options:   []string{"Хорошо", "Плохо"},
+		Status:    "active",
+		Type:      tarantool.PollSingle,
+		Anonymous: true,
+	}
+	text := &tarantool.Poll{
+		PollID:    "text-poll",
+		ChannelID: "poll-channel",
+		Question:  "Идеи для ретро",
+		Status:    "active",
+		Type:      tarantool.PollText,
+	}
+	quiz := &tarantool.Poll{
+		PollID:    "quiz-poll",
+		ChannelID: "poll-channel",
+		Question:  "Столица Австралии?",
+		Options:   []string{"Сидней", "Канберра"},
+		Status:    "active",
+		Type:      tarantool.PollQuiz,
+	}
+	users := []*model.User{{Id: "alice", Username: "alice.k"}, {Id: "bob", Username: "bob.m"}}
+
+	tests := []struct {
+		name       string
+		args       []string
+		setupMocks func()
+		file       string
+		reply      string
+	}{
+		{
+			name: "csv with voters",
+			args: []string{"approval-poll", "csv"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "approval-poll").Return(approval, nil)
+				mockMM.On("GetChannelMember", context.Background(), "poll-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockTarantool.On("GetResults", context.Background(), "approval-poll").Return(ranked(&tarantool.VoteResult{
+					Question: approval.Question,
+					Type:     tarantool.PollApproval,
+					Options:  approval.Options,
+					Votes:    []int{2, 1},
+					Total:    2,
+					Ballots: []tarantool.Vote{
+						{UserID: "alice", Option: "1,2", VotedAt: votedAt},
+						{UserID: "bob", Option: "1", VotedAt: votedAt, Delegated: true},
+					},
+				}), nil)
+				mockMM.On("GetUsersByIds", context.Background(), []string{"alice", "bob"}).Return(users, &model.Response{}, nil)
+			},
+			file: "option,text,votes,percent\n" +
+				"1,Пицца,2,100.00\n" +
+				"2,Суши,1,50.00\n" +
+				"\n" +
+				"username,option,text,value,voted_at,delegated\n" +
+				"alice.k,1,Пицца,1,2026-10-18T09:30:00Z,false\n" +
+				"alice.k,2,Суши,1,2026-10-18T09:30:00Z,false\n" +
+				"bob.m,1,Пицца,1,2026-10-18T09:30:00Z,true\n",
+		},
+		{
+			name: "anonymous json",
+			args: []string{"anonymous-poll", "json"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "anonymous-poll").Return(anonymous, nil)
+				mockMM.On("GetChannelMember", context.Background(), "poll-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockTarantool.On("GetResults", context.Background(), "anonymous-poll").Return(ranked(&tarantool.VoteResult{
+					Options: anonymous.Options,
+					Votes:   []int{1, 2},
+					Total:   3,
+					Ballots: []tarantool.Vote{{UserID: "alice", Option: "1"}},
+				}), nil)
+			},
+			file: `{
+  "poll_id": "anonymous-poll",
+  "question": "Оцените спринт",
+  "type": "single",
+  "status": "active",
+  "total": 3,
+  "options": [
+    {
+      "number": 1,
+      "text": "Хорошо",
+      "votes": 1,
+      "percent": 33.33
+    },
+    {
+      "number": 2,
+      "text": "Плохо",
+      "votes": 2,
+      "percent": 66.67
+    }
+  ]
+}`,
+		},
+		{
+			name: "text answers",
+			args: []string{"text-poll", "csv"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "text-poll").Return(text, nil)
+				mockMM.On("GetChannelMember", context.Background(), "poll-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockTarantool.On("GetAnswers", context.Background(), "text-poll").Return([]tarantool.Answer{
+					{UserID: "bob", Text: "Меньше встреч, больше кода", CreatedAt: votedAt},
+				}, nil)
+				mockMM.On("GetUsersByIds", context.Background(), []string{"bob"}).Return(users[1:], &model.Response{}, nil)
+			},
+			file: "username,text,answered_at\n" +
+				"bob.m,\"Меньше встреч, больше кода\",2026-10-18T09:30:00Z\n",
+		},
+		{
+			name: "active quiz",
+			args: []string{"quiz-poll", "json"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "quiz-poll").Return(quiz, nil)
+				mockMM.On("GetChannelMember", context.Background(), "poll-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+			},
+			reply: "Результаты викторины откроются после завершения",
+		},
+		{
+			name: "not a member",
+			args: []string{"approval-poll", "csv"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "approval-poll").Return(approval, nil)
+				mockMM.On("GetChannelMember", context.Background(), "poll-channel", "test-user", "").Return((*model.ChannelMember)(nil), &model.Response{StatusCode: 404}, errors.New("not found"))
+			},
+			reply: "Выгрузить можно только голосование из канала",
+		},
+		{
+			name:       "unknown format",
+			args:       []string{"approval-poll", "xlsx"},
+			setupMocks: func() {},
+			reply:      "Использование: /export",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			if tc.file != "" {
+				filename := "poll-" + tc.args[0] + "." + tc.args[1]
+				mockMM.On("UploadFile", context.Background(), mock.MatchedBy(func(data []byte) bool {
+					return string(data) == tc.file && (tc.args[1] != "json" || json.Valid(data))
+				}), "test-channel", filename).Return(&model.FileUploadResponse{
+					FileInfos: []*model.FileInfo{{Id: "file-id"}},
+				}, &model.Response{}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "test-channel" &&
+						assert.ObjectsAreEqual(model.StringArray{"file-id"}, post.FileIds)
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			} else {
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return strings.Contains(post.Message, tc.reply)
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			}
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "test-user",
+				ChannelId: "test-channel",
+			}
+
+			bot.handleExport(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+// ranked дополняет результаты местами и процентами, как это делает GetResults.
+func ranked(results *tarantool.VoteResult) *tarantool.VoteResult {
+	results.Rank()
+	return results
+}
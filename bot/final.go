@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"voting-bot/tarantool"
+)
+
+// announceFinalResults публикует итоги завершённого голосования и фиксирует
+// их в хранилище: последующие изменения весов или доверенностей не меняют
+// опубликованный исход. По настройке голосования пост закрепляется, а
+// создатель получает отчёт в личные сообщения.
+func (b *Bot) announceFinalResults(poll *tarantool.Poll, channelId, header string) {
+	response, err := b.formatPollResults(poll)
+	if err != nil {
+		b.logger().Error("Ошибка получения результатов", "poll_id", poll.PollID, "err", err)
+		b.sendReply(channelId, header)
+		return
+	}
+
+	post, err := b.createPost(channelId, header+"\n"+response)
+	if err != nil {
+		b.logger().Error("Ошибка публикации итогов", "poll_id", poll.PollID, "err", err)
+		return
+	}
+
+	err = b.TarantoolClient.SaveFinalResult(b.ctx(), tarantool.FinalResult{
+		PollID:   poll.PollID,
+		Summary:  response,
+		PostID:   post.Id,
+		ClosedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		b.logger().Error("Ошибка сохранения итогов", "poll_id", poll.PollID, "err", err)
+	}
+
+	if poll.PinResults {
+		if _, err := b.Client.PinPost(b.ctx(), post.Id); err != nil {
+			b.logger().Error("Ошибка закрепления итогов", "poll_id", poll.PollID, "err", err)
+		}
+	}
+
+	if poll.CreatorID != "" {
+		b.sendCreatorReport(poll, response)
+	}
+}
+
+// finalSummary возвращает зафиксированные итоги завершённого голосования.
+func (b *Bot) finalSummary(poll *tarantool.Poll) (string, bool) {
+	final, err := b.TarantoolClient.GetFinalResult(b.ctx(), poll.PollID)
+	if err != nil {
+		if !errors.Is(err, tarantool.ErrNotFound) {
+			b.logger().Error("Ошибка получения итогов", "poll_id", poll.PollID, "err", err)
+		}
+		return "", false
+	}
+	return final.Summary, true
+}
+
+// sendCreatorReport присылает создателю полный отчёт: итоги, список
+// участников неанонимного голосования и команду для выгрузки.
+func (b *Bot) sendCreatorReport(poll *tarantool.Poll, response string) {
+	report := fmt.Sprintf("Голосование «%s» завершено.\n%s\n", poll.Question, response)
+
+	voters, err := b.pollVoters(poll)
+	if err != nil {
+		b.logger().Error("Ошибка получения участников", "poll_id", poll.PollID, "err", err)
+	} else {
+		report += fmt.Sprintf("\nУчастников: %d", len(voters))
+		if !poll.Anonymous && len(voters) > 0 {
+			usernames, err := b.usernames(voters)
+			if err != nil {
+				b.logger().Error("Ошибка получения пользователей", "err", err)
+			} else {
+				names := make([]string, 0, len(voters))
+				for _, userID := range voters {
+					if username, ok := usernames[userID]; ok {
+						names = append(names, "@"+username)
+					}
+				}
+				report += ": " + strings.Join(names, ", ")
+			}
+		}
+	}
+	report += fmt.Sprintf("\nВыгрузка для таблиц: `/export %s csv`", poll.PollID)
+
+	channel, _, err := b.Client.CreateDirectChannel(b.ctx(), b.UserID, poll.CreatorID)
+	if err != nil {
+		b.logger().Error("Ошибка создания личного канала", "poll_id", poll.PollID, "user_id", poll.CreatorID, "err", err)
+		return
+	}
+	b.sendReply(channel.Id, report)
+}
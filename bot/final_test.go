@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestAnnounceFinalResultsPinned(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:     "pinned-poll",
+		Status:     "active",
+		ChannelID:  "test-channel",
+		Type:       tarantool.PollText,
+		Question:   "Идеи?",
+		PinResults: true,
+	}
+
+	mockTarantool.On("GetAnswers", context.Background(), "pinned-poll").Return([]tarantool.Answer{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "Голосование завершено!\n**Ответы**: Идеи?\nОтветов пока нет"
+	})).Return(&model.Post{Id: "results-post"}, &model.Response{}, nil)
+	mockTarantool.On("SaveFinalResult", context.Background(), mock.MatchedBy(func(result tarantool.FinalResult) bool {
+		return result.PollID == "pinned-poll" && result.Summary == "**Ответы**: Идеи?\nОтветов пока нет"
+	})).Return(nil)
+	mockMM.On("PinPost", context.Background(), "results-post").Return(&model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.announceFinalResults(poll, "test-channel", "Голосование завершено!")
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestHandleResultsFrozen(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "closed-poll",
+		Status:    "closed",
+		ChannelID: "test-channel",
+	}
+
+	// Итоги берутся из зафиксированной записи, GetResults не вызывается
+	mockTarantool.On("GetPoll", context.Background(), "closed-poll").Return(poll, nil)
+	mockTarantool.On("GetFinalResult", context.Background(), "closed-poll").Return(&tarantool.FinalResult{
+		PollID:  "closed-poll",
+		Summary: "**Результаты голосования**: Test question?\nПобедитель: A",
+	}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "**Результаты голосования**: Test question?\nПобедитель: A"
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.handleResults(&model.Post{UserId: "test-user", ChannelId: "test-channel"}, []string{"closed-poll"})
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"voting-bot/tarantool"
+)
+
+const (
+	// heartbeatInterval — как часто цикл обработки событий отмечается живым.
+	heartbeatInterval = 5 * time.Second
+	// livenessTimeout — сколько цикл может не отмечаться, прежде чем Alive
+	// сочтёт его зависшим.
+	livenessTimeout = 2 * time.Minute
+)
+
+// Check — результат одной проверки готовности.
+type Check struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// beat отмечает, что цикл обработки событий работает.
+func (b *Bot) beat() {
+	b.heartbeat.Store(time.Now().UnixNano())
+}
+
+// Alive проверяет, что цикл обработки событий запущен и не завис: он
+// отмечается каждые heartbeatInterval и при ожидании переподключения.
+func (b *Bot) Alive() error {
+	last := b.heartbeat.Load()
+	if last == 0 {
+		return errors.New("обработка событий не запущена")
+	}
+
+	// Пауза между переподключениями может быть дольше обычного таймаута
+	timeout := max(livenessTimeout, 2*b.MaxReconnectDelay)
+	if since := time.Since(time.Unix(0, last)); since > timeout {
+		return fmt.Errorf("цикл обработки событий не отвечает %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+// Ready проверяет, что бот может выполнять команды: Tarantool отвечает,
+// его схема не старше ожидаемой и websocket-соединение с Mattermost открыто.
+func (b *Bot) Ready(ctx context.Context) ([]Check, bool) {
+	checks := []Check{
+		newCheck("tarantool", b.TarantoolClient.Ping(ctx)),
+		newCheck("schema", b.checkSchema(ctx)),
+		newCheck("mattermost", b.checkConnection()),
+	}
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.OK
+	}
+	return checks, ready
+}
+
+func newCheck(name string, err error) Check {
+	if err != nil {
+		return Check{Name: name, Error: err.Error()}
+	}
+	return Check{Name: name, OK: true}
+}
+
+func (b *Bot) checkSchema(ctx context.Context) error {
+	version, err := b.TarantoolClient.GetSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < tarantool.SchemaVersion {
+		return fmt.Errorf("версия схемы %d, требуется %d: обновите tarantool-config.lua", version, tarantool.SchemaVersion)
+	}
+	return nil
+}
+
+func (b *Bot) checkConnection() error {
+	state := b.ConnectionState()
+	if state.Connected {
+		return nil
+	}
+	if state.LastError != "" {
+		return fmt.Errorf("нет соединения с Mattermost: %s", state.LastError)
+	}
+	return errors.New("нет соединения с Mattermost")
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"voting-bot/tarantool"
+)
+
+func TestAlive(t *testing.T) {
+	bot := &Bot{MaxReconnectDelay: time.Minute}
+	assert.EqualError(t, bot.Alive(), "обработка событий не запущена")
+
+	bot.beat()
+	assert.NoError(t, bot.Alive())
+
+	bot.heartbeat.Store(time.Now().Add(-3 * time.Minute).UnixNano())
+	assert.ErrorContains(t, bot.Alive(), "цикл обработки событий не отвечает")
+
+	// Пауза переподключения дольше обычного таймаута не считается зависанием
+	bot.MaxReconnectDelay = 5 * time.Minute
+	assert.NoError(t, bot.Alive())
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		pingErr   error
+		version   int64
+		connected bool
+		wantReady bool
+		wantErrs  map[string]string
+	}{
+		{
+			name:      "ready",
+			version:   tarantool.SchemaVersion,
+			connected: true,
+			wantReady: true,
+		},
+		{
+			name:      "tarantool down",
+			pingErr:   errors.New("connection refused"),
+			version:   tarantool.SchemaVersion,
+			connected: true,
+			wantErrs:  map[string]string{"tarantool": "connection refused"},
+		},
+		{
+			name:      "old schema",
+			version:   tarantool.SchemaVersion - 1,
+			connected: true,
+			wantErrs:  map[string]string{"schema": "обновите tarantool-config.lua"},
+		},
+		{
+			name:     "disconnected",
+			version:  tarantool.SchemaVersion,
+			wantErrs: map[string]string{"mattermost": "нет соединения с Mattermost: ping timeout"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTarantool := new(MockTarantool)
+			mockTarantool.On("Ping", context.Background()).Return(tc.pingErr)
+			mockTarantool.On("GetSchemaVersion", context.Background()).Return(tc.version, nil)
+
+			bot := &Bot{TarantoolClient: mockTarantool}
+			if tc.connected {
+				bot.conn.set(true, 0, nil)
+			} else {
+				bot.conn.set(false, 1, errors.New("ping timeout"))
+			}
+
+			checks, ready := bot.Ready(context.Background())
+			assert.Equal(t, tc.wantReady, ready)
+			assert.Len(t, checks, 3)
+			for _, check := range checks {
+				if want, ok := tc.wantErrs[check.Name]; ok {
+					assert.False(t, check.OK, check.Name)
+					assert.Contains(t, check.Error, want)
+				} else {
+					assert.True(t, check.OK, check.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/metrics"
+)
+
+// pollCommands — команды, первый аргумент которых ID голосования.
+var pollCommands = map[string]bool{
+	"/vote":       true,
+	"/results":    true,
+	"/endpoll":    true,
+	"/deletepoll": true,
+	"/remind":     true,
+	"/answer":     true,
+	"/promote":    true,
+	"/unvote":     true,
+	"/export":     true,
+	"/clonepoll":  true,
+	"/delegate":   true,
+	"/undelegate": true,
+}
+
+// logger возвращает журнал бота, по умолчанию — slog.Default().
+func (b *Bot) logger() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return slog.Default()
+}
+
+// postLog возвращает журнал команды: ID поста связывает все записи о ней,
+// автор и канал показывают, откуда она пришла.
+func (b *Bot) postLog(post *model.Post) *slog.Logger {
+	logger := b.logger()
+	if run, ok := b.runs.Load(post.Id); ok {
+		logger = slog.New(failureHandler{Handler: logger.Handler(), run: run.(*commandRun)})
+	}
+	return logger.With("post_id", post.Id, "user_id", post.UserId, "channel_id", post.ChannelId)
+}
+
+// commandLog возвращает журнал команды с ID голосования, если команда
+// относится к конкретному голосованию.
+func (b *Bot) commandLog(post *model.Post, command string, args []string) *slog.Logger {
+	logger := b.postLog(post).With("command", command)
+	if pollCommands[command] && len(args) > 0 && args[0] != "channel" {
+		logger = logger.With("poll_id", args[0])
+	}
+	return logger
+}
+
+// finishCommand записывает итог команды и время её выполнения в журнал и
+// метрики. Нераспознанные команды учитываются под одним именем, чтобы
+// произвольный текст не порождал новые ряды метрик.
+func (b *Bot) finishCommand(post *model.Post, command string, args []string, run *commandRun, start time.Time) {
+	b.runs.Delete(post.Id)
+
+	outcome := metrics.OutcomeOK
+	switch {
+	case !run.completed:
+		outcome = metrics.OutcomePanic
+	case run.failed.Load():
+		outcome = metrics.OutcomeError
+	}
+	if run.unknown {
+		command = "unknown"
+	}
+
+	b.metrics.CommandHandled(command, outcome)
+	b.commandLog(post, command, args).Info("Команда обработана", "outcome", outcome, "latency", time.Since(start))
+}
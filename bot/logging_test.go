@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantPoll any
+	}{
+		{name: "poll command", message: "/vote poll1", wantPoll: "poll1"},
+		{name: "channel delegation", message: "/delegate channel", wantPoll: nil},
+		{name: "other command", message: "/leaderboard poll1", wantPoll: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTarantool := new(MockTarantool)
+			mockMM := new(MockMattermostClient)
+
+			mockTarantool.On("ClaimPost", context.Background(), "post", "test-channel", int64(0)).Return(true, nil)
+			mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil).Maybe()
+
+			var out bytes.Buffer
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+				UserID:          "bot-user",
+				Logger:          slog.New(slog.NewJSONHandler(&out, nil)),
+			}
+			bot.handlePost(&model.Post{Id: "post", ChannelId: "test-channel", UserId: "user", Message: tc.message})
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+			assert.Equal(t, "Команда обработана", record["msg"])
+			assert.Equal(t, "post", record["post_id"])
+			assert.Equal(t, "user", record["user_id"])
+			assert.Equal(t, "test-channel", record["channel_id"])
+			assert.Equal(t, tc.wantPoll, record["poll_id"])
+			assert.Contains(t, record, "latency")
+		})
+	}
+}
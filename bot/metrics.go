@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/metrics"
+)
+
+// SetMetrics включает метрики бота. Ошибки API Mattermost считает обёртка
+// над Client.
+func (b *Bot) SetMetrics(m *metrics.Metrics) {
+	b.metrics = m
+	if client, ok := b.Client.(meteredClient); ok {
+		b.Client = client.MattermostClient
+	}
+	b.Client = meteredClient{MattermostClient: b.Client, metrics: m}
+}
+
+// commandRun отмечает, что команда при выполнении записала ошибку в журнал:
+// так команда считается неуспешной в метриках без изменения обработчиков.
+type commandRun struct {
+	failed    atomic.Bool
+	completed bool // false после выхода из обработчика — была паника
+	unknown   bool // команда не распознана
+}
+
+// failureHandler передаёт записи журнала дальше и отмечает команду
+// неуспешной при записи уровня Error, даже если такой уровень отключён.
+type failureHandler struct {
+	slog.Handler
+	run *commandRun
+}
+
+func (h failureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || h.Handler.Enabled(ctx, level)
+}
+
+func (h failureHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		h.run.failed.Store(true)
+	}
+	if !h.Handler.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h failureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return failureHandler{Handler: h.Handler.WithAttrs(attrs), run: h.run}
+}
+
+func (h failureHandler) WithGroup(name string) slog.Handler {
+	return failureHandler{Handler: h.Handler.WithGroup(name), run: h.run}
+}
+
+// meteredClient считает ошибки запросов к API Mattermost по операциям.
+type meteredClient struct {
+	MattermostClient
+	metrics *metrics.Metrics
+}
+
+func (c meteredClient) count(operation string, err error) {
+	if err != nil {
+		c.metrics.MattermostError(operation)
+	}
+}
+
+func (c meteredClient) CreatePost(ctx context.Context, post *model.Post) (*model.Post, *model.Response, error) {
+	result, resp, err := c.MattermostClient.CreatePost(ctx, post)
+	c.count("CreatePost", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetMe(ctx context.Context, etag string) (*model.User, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetMe(ctx, etag)
+	c.count("GetMe", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetChannelMembers(ctx context.Context, channelId string, page, perPage int, etag string) (model.ChannelMembers, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetChannelMembers(ctx, channelId, page, perPage, etag)
+	c.count("GetChannelMembers", err)
+	return result, resp, err
+}
+
+func (c meteredClient) CreateDirectChannel(ctx context.Context, userId1, userId2 string) (*model.Channel, *model.Response, error) {
+	result, resp, err := c.MattermostClient.CreateDirectChannel(ctx, userId1, userId2)
+	c.count("CreateDirectChannel", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetChannelMember(ctx context.Context, channelId, userId, etag string) (*model.ChannelMember, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetChannelMember(ctx, channelId, userId, etag)
+	c.count("GetChannelMember", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetUserByUsername(ctx context.Context, userName, etag string) (*model.User, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetUserByUsername(ctx, userName, etag)
+	c.count("GetUserByUsername", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetGroups(ctx context.Context, opts model.GroupSearchOpts) ([]*model.Group, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetGroups(ctx, opts)
+	c.count("GetGroups", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetGroupMembers(ctx context.Context, groupID string) (*model.GroupMemberList, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetGroupMembers(ctx, groupID)
+	c.count("GetGroupMembers", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetUsersByIds(ctx context.Context, userIds []string) ([]*model.User, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetUsersByIds(ctx, userIds)
+	c.count("GetUsersByIds", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetUser(ctx context.Context, userId, etag string) (*model.User, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetUser(ctx, userId, etag)
+	c.count("GetUser", err)
+	return result, resp, err
+}
+
+func (c meteredClient) SaveReaction(ctx context.Context, reaction *model.Reaction) (*model.Reaction, *model.Response, error) {
+	result, resp, err := c.MattermostClient.SaveReaction(ctx, reaction)
+	c.count("SaveReaction", err)
+	return result, resp, err
+}
+
+func (c meteredClient) CreatePostEphemeral(ctx context.Context, post *model.PostEphemeral) (*model.Post, *model.Response, error) {
+	result, resp, err := c.MattermostClient.CreatePostEphemeral(ctx, post)
+	c.count("CreatePostEphemeral", err)
+	return result, resp, err
+}
+
+func (c meteredClient) UploadFile(ctx context.Context, data []byte, channelId string, filename string) (*model.FileUploadResponse, *model.Response, error) {
+	result, resp, err := c.MattermostClient.UploadFile(ctx, data, channelId, filename)
+	c.count("UploadFile", err)
+	return result, resp, err
+}
+
+func (c meteredClient) PinPost(ctx context.Context, postId string) (*model.Response, error) {
+	resp, err := c.MattermostClient.PinPost(ctx, postId)
+	c.count("PinPost", err)
+	return resp, err
+}
+
+func (c meteredClient) GetTeamsForUser(ctx context.Context, userId, etag string) ([]*model.Team, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetTeamsForUser(ctx, userId, etag)
+	c.count("GetTeamsForUser", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetChannelsForTeamForUser(ctx context.Context, teamId, userId string, includeDeleted bool, etag string) ([]*model.Channel, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetChannelsForTeamForUser(ctx, teamId, userId, includeDeleted, etag)
+	c.count("GetChannelsForTeamForUser", err)
+	return result, resp, err
+}
+
+func (c meteredClient) GetPostsSince(ctx context.Context, channelId string, time int64, collapsedThreads bool) (*model.PostList, *model.Response, error) {
+	result, resp, err := c.MattermostClient.GetPostsSince(ctx, channelId, time, collapsedThreads)
+	c.count("GetPostsSince", err)
+	return result, resp, err
+}
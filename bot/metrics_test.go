@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/metrics"
+)
+
+func TestCommandMetrics(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	mockTarantool.On("ClaimPost", context.Background(), mock.Anything, "test-channel", int64(0)).Return(true, nil)
+	mockTarantool.On("GetQuizResults", context.Background(), "test-channel").Return(nil, errors.New("timeout"))
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "Не удалось получить рейтинг"
+	})).Return((*model.Post)(nil), &model.Response{}, errors.New("server error"))
+	mockMM.On("CreatePost", context.Background(), mock.Anything).Return(&model.Post{}, &model.Response{}, nil)
+
+	reg := prometheus.NewRegistry()
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+	}
+	bot.SetMetrics(metrics.New(reg))
+
+	for i, message := range []string{
+		"/vote poll1",
+		"/vote poll1",
+		// Ошибка Tarantool: команда неуспешна, ответ не доставлен
+		"/leaderboard",
+		"/no-such-command",
+	} {
+		bot.handlePost(&model.Post{Id: string(rune('a' + i)), ChannelId: "test-channel", UserId: "user", Message: message})
+	}
+
+	expected := `
+# HELP voting_bot_commands_total Обработанные команды по имени и итогу (ok, error, panic).
+# TYPE voting_bot_commands_total counter
+voting_bot_commands_total{command="/leaderboard",outcome="error"} 1
+voting_bot_commands_total{command="/vote",outcome="ok"} 2
+voting_bot_commands_total{command="unknown",outcome="ok"} 1
+# HELP voting_bot_mattermost_errors_total Ошибки запросов к API Mattermost по операциям.
+# TYPE voting_bot_mattermost_errors_total counter
+voting_bot_mattermost_errors_total{operation="CreatePost"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"voting_bot_commands_total", "voting_bot_mattermost_errors_total"))
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestCommandMetricsPanic(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+
+	mockTarantool.On("ClaimPost", context.Background(), "post", "test-channel", int64(0)).Return(true, nil)
+	mockTarantool.On("GetQuizResults", context.Background(), "test-channel").Run(func(args mock.Arguments) {
+		panic("malformed tuple")
+	}).Return(nil, nil)
+
+	reg := prometheus.NewRegistry()
+	bot := &Bot{
+		Client:          new(MockMattermostClient),
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+	}
+	bot.SetMetrics(metrics.New(reg))
+
+	func() {
+		defer bot.logPanic("тест", nil)
+		bot.handlePost(&model.Post{Id: "post", ChannelId: "test-channel", UserId: "user", Message: "/leaderboard"})
+	}()
+
+	expected := `
+# HELP voting_bot_commands_total Обработанные команды по имени и итогу (ok, error, panic).
+# TYPE voting_bot_commands_total counter
+voting_bot_commands_total{command="/leaderboard",outcome="panic"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "voting_bot_commands_total"))
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const leaderboardSize = 10
+
+// parseCorrect разбирает номера правильных вариантов викторины: "2" или "1,3".
+func parseCorrect(value string, options int) ([]int, error) {
+	seen := make(map[int]bool)
+	var correct []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > options || seen[n] {
+			return nil, tarantool.ErrInvalidOption
+		}
+		seen[n] = true
+		correct = append(correct, n)
+	}
+	sort.Ints(correct)
+	return correct, nil
+}
+
+// finishQuiz сохраняет итоги завершённой викторины для рейтинга канала и
+// лично сообщает каждому участнику, верно ли он ответил. Голоса по
+// доверенности в викторине не учитываются.
+func (b *Bot) finishQuiz(poll *tarantool.Poll) {
+	votes, err := b.TarantoolClient.GetVotes(b.ctx(), poll.PollID)
+	if err != nil {
+		b.logger().Error("Ошибка получения ответов викторины", "poll_id", poll.PollID, "err", err)
+		return
+	}
+
+	answer := make([]string, len(poll.Correct))
+	for i, optionNum := range poll.Correct {
+		answer[i] = poll.Options[optionNum-1]
+	}
+
+	for _, vote := range votes {
+		correct := poll.IsCorrect(vote.Option)
+		err := b.TarantoolClient.SaveQuizResult(b.ctx(), tarantool.QuizResult{
+			PollID:    poll.PollID,
+			UserID:    vote.UserID,
+			ChannelID: poll.ChannelID,
+			Correct:   correct,
+		})
+		if err != nil {
+			b.logger().Error("Ошибка сохранения итога викторины", "err", err)
+		}
+
+		message := fmt.Sprintf("Викторина «%s» завершена. Ваш ответ верный! ✅", poll.Question)
+		if !correct {
+			message = fmt.Sprintf("Викторина «%s» завершена. Ваш ответ неверный ❌\nПравильный ответ: %s", poll.Question, strings.Join(answer, ", "))
+		}
+		b.sendEphemeral(poll.ChannelID, vote.UserID, message)
+	}
+}
+
+// quizScore — место участника в рейтинге викторин канала.
+type quizScore struct {
+	UserID   string
+	Correct  int
+	Answered int
+}
+
+func (b *Bot) handleLeaderboard(post *model.Post, args []string) {
+	if len(args) != 0 {
+		b.sendReply(post.ChannelId, "Использование: /leaderboard")
+		return
+	}
+
+	results, err := b.TarantoolClient.GetQuizResults(b.ctx(), post.ChannelId)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения итогов викторин", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить рейтинг")
+		return
+	}
+
+	scores := leaderboard(results)
+	if len(scores) == 0 {
+		b.sendReply(post.ChannelId, "В этом канале ещё не было завершённых викторин")
+		return
+	}
+	if len(scores) > leaderboardSize {
+		scores = scores[:leaderboardSize]
+	}
+
+	userIDs := make([]string, len(scores))
+	for i, score := range scores {
+		userIDs[i] = score.UserID
+	}
+	users, _, err := b.Client.GetUsersByIds(b.ctx(), userIDs)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения пользователей", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить рейтинг")
+		return
+	}
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		usernames[user.Id] = "@" + user.Username
+	}
+
+	response := "**Рейтинг викторин канала**:\n"
+	for i, score := range scores {
+		name, ok := usernames[score.UserID]
+		if !ok {
+			name = score.UserID
+		}
+		response += fmt.Sprintf("%d. %s — %d из %d\n", i+1, name, score.Correct, score.Answered)
+	}
+	b.sendReply(post.ChannelId, response)
+}
+
+// leaderboard суммирует итоги викторин по участникам: выше тот, у кого
+// больше правильных ответов, при равенстве — меньше ошибок.
+func leaderboard(results []tarantool.QuizResult) []quizScore {
+	byUser := make(map[string]*quizScore)
+	var scores []*quizScore
+	for _, result := range results {
+		score, ok := byUser[result.UserID]
+		if !ok {
+			score = &quizScore{UserID: result.UserID}
+			byUser[result.UserID] = score
+			scores = append(scores, score)
+		}
+
+		score.Answered++
+		if result.Correct {
+			score.Correct++
+		}
+	}
+
+	sorted := make([]quizScore, len(scores))
+	for i, score := range scores {
+		sorted[i] = *score
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Correct != sorted[j].Correct {
+			return sorted[i].Correct > sorted[j].Correct
+		}
+		return sorted[i].Answered < sorted[j].Answered
+	})
+	return sorted
+}
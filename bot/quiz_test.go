@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestFinishQuiz(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "quiz-poll",
+		Question:  "Что делать с фишинговым письмом?",
+		Options:   []string{"Открыть вложение", "Сообщить в ИБ", "Переслать коллегам"},
+		ChannelID: "test-channel",
+		Type:      tarantool.PollQuiz,
+		Correct:   []int{2},
+	}
+
+	mockTarantool.On("GetVotes", context.Background(), "quiz-poll").Return([]tarantool.Vote{
+		{PollID: "quiz-poll", UserID: "alice", Option: "2"},
+		{PollID: "quiz-poll", UserID: "bob", Option: "1"},
+	}, nil)
+	mockTarantool.On("SaveQuizResult", context.Background(), tarantool.QuizResult{
+		PollID: "quiz-poll", UserID: "alice", ChannelID: "test-channel", Correct: true,
+	}).Return(nil)
+	mockTarantool.On("SaveQuizResult", context.Background(), tarantool.QuizResult{
+		PollID: "quiz-poll", UserID: "bob", ChannelID: "test-channel", Correct: false,
+	}).Return(nil)
+	mockMM.On("CreatePostEphemeral", context.Background(), mock.MatchedBy(func(post *model.PostEphemeral) bool {
+		return post.UserID == "alice" &&
+			post.Post.ChannelId == "test-channel" &&
+			strings.Contains(post.Post.Message, "Ваш ответ верный")
+	})).Return(&model.Post{}, &model.Response{}, nil)
+	mockMM.On("CreatePostEphemeral", context.Background(), mock.MatchedBy(func(post *model.PostEphemeral) bool {
+		return post.UserID == "bob" &&
+			strings.Contains(post.Post.Message, "Правильный ответ: Сообщить в ИБ")
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.finishQuiz(poll)
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestHandleLeaderboard(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	mockTarantool.On("GetQuizResults", context.Background(), "test-channel").Return([]tarantool.QuizResult{
+		{PollID: "q1", UserID: "bob", Correct: true},
+		{PollID: "q1", UserID: "alice", Correct: true},
+		{PollID: "q2", UserID: "alice", Correct: true},
+		{PollID: "q2", UserID: "bob", Correct: false},
+		{PollID: "q2", UserID: "carol", Correct: true},
+	}, nil)
+	mockMM.On("GetUsersByIds", context.Background(), []string{"alice", "carol", "bob"}).Return([]*model.User{
+		{Id: "alice", Username: "alice"},
+		{Id: "bob", Username: "bob"},
+		{Id: "carol", Username: "carol"},
+	}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return strings.Contains(post.Message, "1. @alice — 2 из 2\n2. @carol — 1 из 1\n3. @bob — 1 из 2")
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	post := &model.Post{
+		UserId:    "test-user",
+		ChannelId: "test-channel",
+		Message:   "/leaderboard",
+	}
+
+	bot.handleLeaderboard(post, nil)
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestFormatQuizResults(t *testing.T) {
+	results := &tarantool.VoteResult{
+		Question:     "2+2?",
+		Status:       "active",
+		Type:         tarantool.PollQuiz,
+		Options:      []string{"3", "4"},
+		Correct:      []int{2},
+		Votes:        []int{1, 2},
+		Total:        3,
+		CorrectVotes: 2,
+	}
+
+	response := formatResults(results)
+	assert.Contains(t, response, "Ответов: 3")
+	assert.NotContains(t, response, "✅")
+
+	results.Status = "closed"
+	response = formatResults(results)
+	assert.Contains(t, response, "2. 4 - 2 голосов ✅")
+	assert.Contains(t, response, "Правильных ответов: 2")
+}
@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+// optionEmojis — реакции для вариантов 1–10 в порядке номеров.
+var optionEmojis = []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "keycap_ten"}
+
+// votesByReaction сообщает, можно ли голосовать в голосовании реакциями:
+// только выбором вариантов и не больше, чем есть цифровых эмодзи.
+func votesByReaction(poll *tarantool.Poll) bool {
+	switch poll.Type {
+	case tarantool.PollSingle, tarantool.PollApproval, tarantool.PollQuiz:
+		return len(poll.Options) <= len(optionEmojis)
+	}
+	return false
+}
+
+// seedReactions ставит под постом голосования реакции всех вариантов,
+// чтобы участникам оставалось только нажать на нужную.
+func (b *Bot) seedReactions(poll *tarantool.Poll, postID string) {
+	for i := range poll.Options {
+		_, _, err := b.Client.SaveReaction(b.ctx(), &model.Reaction{
+			UserId:    b.UserID,
+			PostId:    postID,
+			EmojiName: optionEmojis[i],
+		})
+		if err != nil {
+			b.logger().Error("Ошибка добавления реакции к голосованию", "poll_id", poll.PollID, "err", err)
+			return
+		}
+	}
+}
+
+// parseReactionEvent достаёт реакцию из события о её добавлении или снятии.
+func (b *Bot) parseReactionEvent(event *model.WebSocketEvent) (*model.Reaction, bool) {
+	reactionData, ok := event.GetData()["reaction"].(string)
+	if !ok {
+		return nil, false
+	}
+
+	var reaction *model.Reaction
+	if err := json.Unmarshal([]byte(reactionData), &reaction); err != nil {
+		b.logger().Error("Ошибка разбора реакции", "err", err)
+		return nil, false
+	}
+	return reaction, reaction != nil
+}
+
+func (b *Bot) handleReaction(reaction *model.Reaction, added bool) {
+	if reaction.UserId == b.UserID {
+		return
+	}
+
+	optionNum := slices.Index(optionEmojis, reaction.EmojiName) + 1
+	if optionNum == 0 {
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPollByPost(b.ctx(), reaction.PostId)
+	if errors.Is(err, tarantool.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		b.logger().Error("Ошибка получения голосования по посту", "post_id", reaction.PostId, "err", err)
+		return
+	}
+
+	if poll.Status != "active" || !votesByReaction(poll) || optionNum > len(poll.Options) {
+		return
+	}
+
+	b.applyReaction(poll, reaction.UserId, optionNum, added)
+}
+
+// applyReaction меняет голос участника по поставленной или снятой реакции.
+// В голосовании с одним вариантом новая реакция заменяет прежний выбор.
+func (b *Bot) applyReaction(poll *tarantool.Poll, userID string, optionNum int, added bool) {
+	current, err := b.TarantoolClient.GetVote(b.ctx(), poll.PollID, userID)
+	if err != nil && !errors.Is(err, tarantool.ErrNotFound) {
+		b.logger().Error("Ошибка получения голоса", "err", err)
+		return
+	}
+
+	choices := make(map[int]bool)
+	if current != nil && poll.MultipleChoice() {
+		ballot, err := poll.DecodeBallot(current.Option)
+		if err == nil {
+			for n := range ballot {
+				choices[n] = true
+			}
+		}
+	}
+
+	switch {
+	case added && poll.MultipleChoice():
+		choices[optionNum] = true
+	case added:
+		choices = map[int]bool{optionNum: true}
+	case poll.MultipleChoice():
+		delete(choices, optionNum)
+	case current == nil || current.Option != strconv.Itoa(optionNum):
+		// Снята реакция не с того варианта, за который засчитан голос
+		return
+	}
+
+	if len(choices) == 0 {
+		if current == nil {
+			return
+		}
+		if err := b.TarantoolClient.DeleteVote(b.ctx(), poll.PollID, userID); err != nil {
+			b.logger().Error("Ошибка отмены голоса", "err", err)
+		}
+		return
+	}
+
+	parts := make([]string, 0, len(choices))
+	for n := range choices {
+		parts = append(parts, strconv.Itoa(n))
+	}
+
+	option, err := poll.EncodeBallot(parts)
+	if err != nil {
+		return
+	}
+
+	if err := b.TarantoolClient.AddVote(b.ctx(), poll.PollID, userID, option); err != nil {
+		b.logger().Error("Ошибка голосования реакцией", "err", err)
+		return
+	}
+	b.metrics.VoteCast()
+}
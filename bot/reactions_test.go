@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/require"
+	"voting-bot/tarantool"
+)
+
+func TestDispatchReaction(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	single := &tarantool.Poll{
+		PollID:  "single-poll",
+		Status:  "active",
+		Type:    tarantool.PollSingle,
+		Options: []string{"A", "B", "C"},
+	}
+	approval := &tarantool.Poll{
+		PollID:  "approval-poll",
+		Status:  "active",
+		Type:    tarantool.PollApproval,
+		Options: []string{"A", "B", "C"},
+	}
+	closed := &tarantool.Poll{
+		PollID:  "closed-poll",
+		Status:  "closed",
+		Type:    tarantool.PollSingle,
+		Options: []string{"A", "B"},
+	}
+
+	tests := []struct {
+		name       string
+		event      model.WebsocketEventType
+		reaction   model.Reaction
+		setupMocks func()
+	}{
+		{
+			name:     "vote in single choice poll",
+			event:    model.WebsocketEventReactionAdded,
+			reaction: model.Reaction{UserId: "voter", PostId: "single-post", EmojiName: "two"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "single-post").Return(single, nil)
+				mockTarantool.On("GetVote", context.Background(), "single-poll", "voter").Return(&tarantool.Vote{Option: "1"}, nil)
+				mockTarantool.On("AddVote", context.Background(), "single-poll", "voter", "2").Return(nil)
+			},
+		},
+		{
+			name:     "remove current choice",
+			event:    model.WebsocketEventReactionRemoved,
+			reaction: model.Reaction{UserId: "voter", PostId: "single-post", EmojiName: "two"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "single-post").Return(single, nil)
+				mockTarantool.On("GetVote", context.Background(), "single-poll", "voter").Return(&tarantool.Vote{Option: "2"}, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "single-poll", "voter").Return(nil)
+			},
+		},
+		{
+			name:     "remove replaced choice",
+			event:    model.WebsocketEventReactionRemoved,
+			reaction: model.Reaction{UserId: "voter", PostId: "single-post", EmojiName: "one"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "single-post").Return(single, nil)
+				mockTarantool.On("GetVote", context.Background(), "single-poll", "voter").Return(&tarantool.Vote{Option: "2"}, nil)
+			},
+		},
+		{
+			name:     "add approval",
+			event:    model.WebsocketEventReactionAdded,
+			reaction: model.Reaction{UserId: "voter", PostId: "approval-post", EmojiName: "three"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "approval-post").Return(approval, nil)
+				mockTarantool.On("GetVote", context.Background(), "approval-poll", "voter").Return(&tarantool.Vote{Option: "1"}, nil)
+				mockTarantool.On("AddVote", context.Background(), "approval-poll", "voter", "1,3").Return(nil)
+			},
+		},
+		{
+			name:     "remove last approval",
+			event:    model.WebsocketEventReactionRemoved,
+			reaction: model.Reaction{UserId: "voter", PostId: "approval-post", EmojiName: "one"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "approval-post").Return(approval, nil)
+				mockTarantool.On("GetVote", context.Background(), "approval-poll", "voter").Return(&tarantool.Vote{Option: "1"}, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "approval-poll", "voter").Return(nil)
+			},
+		},
+		{
+			name:     "closed poll",
+			event:    model.WebsocketEventReactionAdded,
+			reaction: model.Reaction{UserId: "voter", PostId: "closed-post", EmojiName: "one"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "closed-post").Return(closed, nil)
+			},
+		},
+		{
+			name:     "option out of range",
+			event:    model.WebsocketEventReactionAdded,
+			reaction: model.Reaction{UserId: "voter", PostId: "single-post", EmojiName: "five"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "single-post").Return(single, nil)
+			},
+		},
+		{
+			name:     "not a poll post",
+			event:    model.WebsocketEventReactionAdded,
+			reaction: model.Reaction{UserId: "voter", PostId: "other-post", EmojiName: "one"},
+			setupMocks: func() {
+				mockTarantool.On("GetPollByPost", context.Background(), "other-post").Return(nil, tarantool.ErrNotFound)
+			},
+		},
+		{
+			name:       "other emoji",
+			event:      model.WebsocketEventReactionAdded,
+			reaction:   model.Reaction{UserId: "voter", PostId: "single-post", EmojiName: "thumbsup"},
+			setupMocks: func() {},
+		},
+		{
+			name:       "bot seeding reactions",
+			event:      model.WebsocketEventReactionAdded,
+			reaction:   model.Reaction{UserId: "bot-user", PostId: "single-post", EmojiName: "one"},
+			setupMocks: func() {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+				UserID:          "bot-user",
+				pool:            newWorkerPool(1, 1, slog.Default(), nil),
+			}
+
+			reactionData, err := json.Marshal(tc.reaction)
+			require.NoError(t, err)
+			event := model.NewWebSocketEvent(tc.event, "", "test-channel", "", nil, "")
+			event.Add("reaction", string(reactionData))
+
+			require.NoError(t, bot.dispatch(context.Background(), event))
+			bot.pool.stop()
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
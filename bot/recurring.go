@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+func (b *Bot) handleSchedulePoll(post *model.Post, args []string) {
+	if len(args) != 2 {
+		b.sendReply(post.ChannelId, "Использование: /schedulepoll \"0 10 * * MON\" ИМЯ_ШАБЛОНА")
+		return
+	}
+
+	schedule, err := parseCron(args[0])
+	if err != nil {
+		b.sendReply(post.ChannelId, "Неверное расписание: нужно пять полей cron — минута, час, день месяца, месяц, день недели")
+		return
+	}
+
+	if _, err := b.TarantoolClient.GetTemplate(b.ctx(), args[1]); err != nil {
+		if !errors.Is(err, tarantool.ErrNotFound) {
+			b.postLog(post).Error("Ошибка получения шаблона", "err", err)
+		}
+		b.sendReply(post.ChannelId, fmt.Sprintf("Шаблон %s не найден", args[1]))
+		return
+	}
+
+	loc := b.userLocation(post.UserId)
+	next := schedule.next(time.Now().In(loc))
+	if next.IsZero() {
+		b.sendReply(post.ChannelId, "Расписание никогда не сработает")
+		return
+	}
+
+	recurrence := tarantool.Recurrence{
+		ID:        model.NewId(),
+		ChannelID: post.ChannelId,
+		CreatorID: post.UserId,
+		Cron:      args[0],
+		Timezone:  loc.String(),
+		Template:  args[1],
+		NextRun:   next.Unix(),
+	}
+	if err := b.TarantoolClient.AddRecurrence(b.ctx(), recurrence); err != nil {
+		b.postLog(post).Error("Ошибка сохранения расписания", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось сохранить расписание")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Регулярное голосование запланировано\nID: `%s`\nПервый запуск: %s (%s)",
+		recurrence.ID, next.Format("02.01.2006 15:04"), recurrence.Timezone))
+}
+
+func (b *Bot) handleSchedules(post *model.Post, args []string) {
+	switch {
+	case len(args) == 0:
+		b.listRecurrences(post)
+	case len(args) == 2 && args[0] == "cancel":
+		b.cancelRecurrence(post, args[1])
+	default:
+		b.sendReply(post.ChannelId, "Использование:\n/schedules\n/schedules cancel ID_РАСПИСАНИЯ")
+	}
+}
+
+func (b *Bot) listRecurrences(post *model.Post) {
+	recurrences, err := b.TarantoolClient.GetRecurrences(b.ctx(), post.ChannelId)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения расписаний", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить расписания")
+		return
+	}
+
+	if len(recurrences) == 0 {
+		b.sendReply(post.ChannelId, "В канале нет регулярных голосований")
+		return
+	}
+
+	response := "**Регулярные голосования**:\n"
+	for _, recurrence := range recurrences {
+		next := time.Unix(recurrence.NextRun, 0).In(recurrenceLocation(recurrence))
+		response += fmt.Sprintf("- `%s` — шаблон %s, `%s` (%s), следующий запуск %s\n",
+			recurrence.ID, recurrence.Template, recurrence.Cron, recurrence.Timezone, next.Format("02.01.2006 15:04"))
+	}
+	b.sendReply(post.ChannelId, response)
+}
+
+func (b *Bot) cancelRecurrence(post *model.Post, id string) {
+	recurrence, err := b.TarantoolClient.GetRecurrence(b.ctx(), id)
+	if err != nil || recurrence.ChannelID != post.ChannelId {
+		b.sendReply(post.ChannelId, "Расписание не найдено")
+		return
+	}
+
+	if recurrence.CreatorID != post.UserId {
+		b.sendReply(post.ChannelId, "Только автор может отменить расписание")
+		return
+	}
+
+	if err := b.TarantoolClient.DeleteRecurrence(b.ctx(), id); err != nil {
+		b.postLog(post).Error("Ошибка удаления расписания", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось отменить расписание")
+		return
+	}
+
+	b.sendReply(post.ChannelId, "Расписание отменено")
+}
+
+// processRecurrences запускает регулярные голосования, время которых
+// наступило. Пропущенные во время простоя запуски не догоняются: голосование
+// создаётся один раз, а следующий запуск отсчитывается от now.
+func (b *Bot) processRecurrences(now time.Time) {
+	recurrences, err := b.TarantoolClient.GetDueRecurrences(b.ctx(), now.Unix())
+	if err != nil {
+		b.logger().Error("Ошибка получения расписаний", "err", err)
+		return
+	}
+
+	for _, recurrence := range recurrences {
+		schedule, err := parseCron(recurrence.Cron)
+		if err != nil {
+			b.logger().Error("Неверное расписание", "recurrence_id", recurrence.ID, "err", err)
+			continue
+		}
+
+		next := schedule.next(now.In(recurrenceLocation(recurrence)))
+		if next.IsZero() {
+			continue
+		}
+
+		claimed, err := b.TarantoolClient.ClaimRecurrence(b.ctx(), recurrence.ID, recurrence.NextRun, next.Unix())
+		if err != nil {
+			b.logger().Error("Ошибка переноса расписания", "recurrence_id", recurrence.ID, "err", err)
+			continue
+		}
+		if !claimed {
+			// Запуск уже выполнила другая реплика бота
+			continue
+		}
+
+		template, err := b.TarantoolClient.GetTemplate(b.ctx(), recurrence.Template)
+		if err != nil {
+			b.logger().Error("Ошибка получения шаблона для расписания", "template", recurrence.Template, "recurrence_id", recurrence.ID, "err", err)
+			continue
+		}
+
+		b.launchCopy(&model.Post{UserId: recurrence.CreatorID, ChannelId: recurrence.ChannelID}, template.Poll)
+	}
+}
+
+func recurrenceLocation(recurrence tarantool.Recurrence) *time.Location {
+	loc, err := time.LoadLocation(recurrence.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
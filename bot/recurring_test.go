@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleSchedulePoll(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	creator := &model.User{
+		Id:       "test-user",
+		Timezone: model.StringMap{"useAutomaticTimezone": "false", "manualTimezone": "Europe/Moscow"},
+	}
+	template := &tarantool.Template{Name: "standup", CreatorID: "test-user", Poll: &tarantool.Poll{Question: "Как дела?"}}
+
+	tests := []struct {
+		name       string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name: "success",
+			args: []string{"0 10 * * MON", "standup"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplate", context.Background(), "standup").Return(template, nil)
+				mockMM.On("GetUser", context.Background(), "test-user", "").Return(creator, &model.Response{}, nil)
+				mockTarantool.On("AddRecurrence", context.Background(), mock.MatchedBy(func(recurrence tarantool.Recurrence) bool {
+					next := time.Unix(recurrence.NextRun, 0).In(time.FixedZone("MSK", 3*60*60))
+					return recurrence.ChannelID == "test-channel" &&
+						recurrence.CreatorID == "test-user" &&
+						recurrence.Cron == "0 10 * * MON" &&
+						recurrence.Timezone == "Europe/Moscow" &&
+						recurrence.Template == "standup" &&
+						next.Weekday() == time.Monday && next.Hour() == 10 && next.Minute() == 0
+				})).Return(nil)
+			},
+			reply: "Регулярное голосование запланировано",
+		},
+		{
+			name:       "invalid cron",
+			args:       []string{"0 10 * MON", "standup"},
+			setupMocks: func() {},
+			reply:      "Неверное расписание",
+		},
+		{
+			name: "unknown template",
+			args: []string{"0 10 * * MON", "retro"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplate", context.Background(), "retro").Return(nil, tarantool.ErrNotFound)
+			},
+			reply: "Шаблон retro не найден",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "test-user",
+				ChannelId: "test-channel",
+			}
+
+			bot.handleSchedulePoll(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestHandleSchedules(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	recurrence := &tarantool.Recurrence{
+		ID:        "weekly",
+		ChannelID: "test-channel",
+		CreatorID: "creator-user",
+		Cron:      "0 10 * * MON",
+		Timezone:  "Europe/Moscow",
+		Template:  "standup",
+		NextRun:   time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC).Unix(),
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:   "list",
+			userID: "test-user",
+			setupMocks: func() {
+				mockTarantool.On("GetRecurrences", context.Background(), "test-channel").Return([]tarantool.Recurrence{*recurrence}, nil)
+			},
+			reply: "- `weekly` — шаблон standup, `0 10 * * MON` (Europe/Moscow), следующий запуск 19.10.2026 10:00",
+		},
+		{
+			name:   "cancel by creator",
+			userID: "creator-user",
+			args:   []string{"cancel", "weekly"},
+			setupMocks: func() {
+				mockTarantool.On("GetRecurrence", context.Background(), "weekly").Return(recurrence, nil)
+				mockTarantool.On("DeleteRecurrence", context.Background(), "weekly").Return(nil)
+			},
+			reply: "Расписание отменено",
+		},
+		{
+			name:   "cancel by other user",
+			userID: "test-user",
+			args:   []string{"cancel", "weekly"},
+			setupMocks: func() {
+				mockTarantool.On("GetRecurrence", context.Background(), "weekly").Return(recurrence, nil)
+			},
+			reply: "Только автор может отменить расписание",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    tc.userID,
+				ChannelId: "test-channel",
+			}
+
+			bot.handleSchedules(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestProcessRecurrences(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	// Понедельник, 10:00 по Москве
+	now := time.Date(2026, 10, 19, 7, 0, 5, 0, time.UTC)
+	due := now.Add(-5 * time.Second).Unix()
+	nextWeek := time.Date(2026, 10, 26, 7, 0, 0, 0, time.UTC).Unix()
+
+	recurrences := []tarantool.Recurrence{
+		{ID: "ours", ChannelID: "test-channel", CreatorID: "creator-user", Cron: "0 10 * * MON", Timezone: "Europe/Moscow", Template: "standup", NextRun: due},
+		{ID: "taken", ChannelID: "test-channel", CreatorID: "creator-user", Cron: "0 10 * * MON", Timezone: "Europe/Moscow", Template: "standup", NextRun: due},
+	}
+	template := &tarantool.Template{Name: "standup", CreatorID: "creator-user", Poll: &tarantool.Poll{
+		Question: "Как дела?",
+		Options:  []string{"Хорошо", "Плохо"},
+		Type:     tarantool.PollSingle,
+	}}
+
+	mockTarantool.On("GetDueRecurrences", context.Background(), now.Unix()).Return(recurrences, nil)
+	mockTarantool.On("ClaimRecurrence", context.Background(), "ours", due, nextWeek).Return(true, nil)
+	mockTarantool.On("ClaimRecurrence", context.Background(), "taken", due, nextWeek).Return(false, nil)
+	mockTarantool.On("GetTemplate", context.Background(), "standup").Return(template, nil).Once()
+	mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(poll *tarantool.Poll) bool {
+		return poll.Question == "Как дела?" &&
+			poll.CreatorID == "creator-user" &&
+			poll.ChannelID == "test-channel"
+	})).Return(nil).Once()
+	mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+	mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "test-channel" && strings.Contains(post.Message, "Как дела?")
+	})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil).Once()
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.processRecurrences(now)
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const (
+	defaultReminderDelay = 200 * time.Millisecond
+	channelMembersPage   = 200
+)
+
+func (b *Bot) handleRemind(post *model.Post, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		b.sendReply(post.ChannelId, "Использование: /remind ID_ГОЛОСОВАНИЯ [ЗА_СКОЛЬКО_ДО_СРОКА]")
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	if poll.CreatorID != post.UserId {
+		b.sendReply(post.ChannelId, "Только создатель может отправлять напоминания")
+		return
+	}
+
+	if poll.Status != "active" {
+		b.sendReply(post.ChannelId, "Голосование уже завершено")
+		return
+	}
+
+	if poll.ChannelID == "" {
+		b.sendReply(post.ChannelId, "Для этого голосования неизвестен канал")
+		return
+	}
+
+	if len(args) == 2 {
+		before, err := time.ParseDuration(args[1])
+		if err != nil || before <= 0 {
+			b.sendReply(post.ChannelId, "Неверная длительность, например: 1h, 30m")
+			return
+		}
+		b.scheduleReminder(post.ChannelId, poll, before)
+		return
+	}
+
+	users, err := b.nonVoters(poll)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения участников канала", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить список участников канала")
+		return
+	}
+
+	if len(users) == 0 {
+		b.sendReply(post.ChannelId, "Все участники канала уже проголосовали")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Отправляю напоминания: %d", len(users)))
+	b.goBackground("рассылка напоминаний "+poll.PollID, func() {
+		b.sendReminders(poll, users)
+	})
+}
+
+// scheduleReminder откладывает напоминание на момент за before до срока голосования.
+func (b *Bot) scheduleReminder(channelId string, poll *tarantool.Poll, before time.Duration) {
+	if poll.Deadline == 0 {
+		b.sendReply(channelId, "У голосования нет срока, напоминание запланировать нельзя")
+		return
+	}
+
+	remindAt := time.Unix(poll.Deadline, 0).Add(-before)
+	if !remindAt.After(time.Now()) {
+		b.sendReply(channelId, "Время напоминания уже прошло")
+		return
+	}
+
+	if err := b.TarantoolClient.AddReminder(b.ctx(), poll.PollID, remindAt.Unix()); err != nil {
+		b.logger().Error("Ошибка сохранения напоминания", "err", err)
+		b.sendReply(channelId, "Не удалось запланировать напоминание")
+		return
+	}
+
+	b.sendReply(channelId, fmt.Sprintf("Напоминание запланировано на %s", remindAt.Format("02.01.2006 15:04")))
+}
+
+// nonVoters возвращает участников канала голосования, которые ещё не проголосовали.
+func (b *Bot) nonVoters(poll *tarantool.Poll) ([]string, error) {
+	voters, err := b.pollVoters(poll)
+	if err != nil {
+		return nil, err
+	}
+
+	voted := make(map[string]bool, len(voters))
+	for _, userID := range voters {
+		voted[userID] = true
+	}
+
+	members, err := b.channelMembers(poll.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, userID := range members {
+		if !voted[userID] {
+			users = append(users, userID)
+		}
+	}
+	return users, nil
+}
+
+// pollVoters возвращает участников, уже проголосовавших или ответивших.
+func (b *Bot) pollVoters(poll *tarantool.Poll) ([]string, error) {
+	if poll.Type != tarantool.PollText {
+		return b.TarantoolClient.GetVoters(b.ctx(), poll.PollID)
+	}
+
+	answers, err := b.TarantoolClient.GetAnswers(b.ctx(), poll.PollID)
+	if err != nil {
+		return nil, err
+	}
+
+	voters := make([]string, len(answers))
+	for i, answer := range answers {
+		voters[i] = answer.UserID
+	}
+	return voters, nil
+}
+
+// channelMembers постранично получает участников канала, кроме самого бота.
+func (b *Bot) channelMembers(channelId string) ([]string, error) {
+	var users []string
+	for page := 0; ; page++ {
+		members, _, err := b.Client.GetChannelMembers(b.ctx(), channelId, page, channelMembersPage, "")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if member.UserId != b.UserID {
+				users = append(users, member.UserId)
+			}
+		}
+
+		if len(members) < channelMembersPage {
+			return users, nil
+		}
+	}
+}
+
+// sendReminders рассылает личные сообщения с паузой ReminderDelay,
+// чтобы не перегружать сервер в больших каналах. Рассылка прерывается,
+// если при остановке бота истёк ShutdownTimeout.
+func (b *Bot) sendReminders(poll *tarantool.Poll, users []string) int {
+	message := fmt.Sprintf("Напоминание: вы ещё не проголосовали в голосовании «%s».\n%s", poll.Question, b.pollLink(poll))
+
+	sent := 0
+	for i, userID := range users {
+		if i > 0 && b.ReminderDelay > 0 {
+			select {
+			case <-time.After(b.ReminderDelay):
+			case <-b.ctx().Done():
+				b.logger().Warn("Рассылка напоминаний прервана", "poll_id", poll.PollID, "sent", sent, "total", len(users))
+				return sent
+			}
+		}
+
+		channel, _, err := b.Client.CreateDirectChannel(b.ctx(), b.UserID, userID)
+		if err != nil {
+			b.logger().Error("Ошибка создания личного канала", "poll_id", poll.PollID, "user_id", userID, "err", err)
+			continue
+		}
+
+		if _, err := b.createPost(channel.Id, message); err != nil {
+			b.logger().Error("Ошибка отправки напоминания", "poll_id", poll.PollID, "user_id", userID, "err", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+func (b *Bot) pollLink(poll *tarantool.Poll) string {
+	if poll.PostID == "" {
+		if poll.Type == tarantool.PollText {
+			return fmt.Sprintf("Ответить: `/answer %s ТЕКСТ`", poll.PollID)
+		}
+		return fmt.Sprintf("Проголосовать: `/vote %s НОМЕР_ВАРИАНТА`", poll.PollID)
+	}
+	return fmt.Sprintf("Голосование: %s/_redirect/pl/%s", strings.TrimRight(b.ServerURL, "/"), poll.PostID)
+}
+
+// processReminders забирает наступившие напоминания и рассылает их в фоне,
+// как /remind: рассылка по большому каналу длится минуты и не должна
+// задерживать завершение голосований и регулярные запуски.
+func (b *Bot) processReminders(now time.Time) {
+	reminders, err := b.TarantoolClient.GetDueReminders(b.ctx(), now.Unix())
+	if err != nil {
+		b.logger().Error("Ошибка получения напоминаний", "err", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		claimed, err := b.TarantoolClient.ClaimReminder(b.ctx(), reminder.PollID, reminder.RemindAt)
+		if err != nil {
+			b.logger().Error("Ошибка удаления напоминания", "poll_id", reminder.PollID, "err", err)
+			continue
+		}
+		if !claimed {
+			// Напоминание уже рассылает другая реплика
+			continue
+		}
+
+		pollID := reminder.PollID
+		b.goBackground("рассылка напоминаний "+pollID, func() {
+			b.sendScheduledReminder(pollID)
+		})
+	}
+}
+
+// sendScheduledReminder рассылает запланированное напоминание тем, кто ещё
+// не проголосовал, если голосование всё ещё активно.
+func (b *Bot) sendScheduledReminder(pollID string) {
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
+	if err != nil && !errors.Is(err, tarantool.ErrNotFound) {
+		b.logger().Error("Ошибка получения голосования", "poll_id", pollID, "err", err)
+		return
+	}
+
+	if poll == nil || poll.Status != "active" || poll.ChannelID == "" {
+		return
+	}
+
+	users, err := b.nonVoters(poll)
+	if err != nil {
+		b.logger().Error("Ошибка получения участников канала", "err", err)
+		return
+	}
+	b.sendReminders(poll, users)
+}
@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleRemind(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		CreatorID: "creator-user",
+		Question:  "Test question?",
+		Options:   []string{"A", "B"},
+		Status:    "active",
+		ChannelID: "test-channel",
+		Deadline:  time.Now().Add(24 * time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:   "everyone voted",
+			userID: "creator-user",
+			args:   []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("GetVoters", context.Background(), "test-poll").Return([]string{"creator-user", "voter-user"}, nil)
+				mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(model.ChannelMembers{
+					{UserId: "creator-user"},
+					{UserId: "voter-user"},
+					{UserId: "bot-user"},
+				}, &model.Response{}, nil)
+			},
+			reply: "Все участники канала уже проголосовали",
+		},
+		{
+			name:   "scheduled reminder",
+			userID: "creator-user",
+			args:   []string{"test-poll", "1h"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("AddReminder", context.Background(), "test-poll", poll.Deadline-3600).Return(nil)
+			},
+			reply: "Напоминание запланировано",
+		},
+		{
+			name:   "non-creator fails",
+			userID: "other-user",
+			args:   []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+			},
+			reply: "Только создатель может отправлять напоминания",
+		},
+		{
+			name:   "invalid poll",
+			userID: "creator-user",
+			args:   []string{"invalid-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "invalid-poll").Return(nil, tarantool.ErrNotFound)
+			},
+			reply: "Голосование не найдено",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+				UserID:          "bot-user",
+			}
+
+			post := &model.Post{
+				UserId:    tc.userID,
+				ChannelId: "test-channel",
+				Message:   "/remind " + strings.Join(tc.args, " "),
+			}
+
+			bot.handleRemind(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestSendReminders(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		Question:  "Test question?",
+		ChannelID: "test-channel",
+		PostID:    "poll-post",
+	}
+
+	mockTarantool.On("GetVoters", context.Background(), "test-poll").Return([]string{"voter-user"}, nil)
+	mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(model.ChannelMembers{
+		{UserId: "voter-user"},
+		{UserId: "lazy-user"},
+		{UserId: "bot-user"},
+	}, &model.Response{}, nil)
+	mockMM.On("CreateDirectChannel", context.Background(), "bot-user", "lazy-user").Return(&model.Channel{Id: "dm-channel"}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "dm-channel" &&
+			strings.Contains(post.Message, "Test question?") &&
+			strings.Contains(post.Message, "https://mm.example.com/_redirect/pl/poll-post")
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+		ServerURL:       "https://mm.example.com/",
+	}
+
+	users, err := bot.nonVoters(poll)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lazy-user"}, users)
+
+	assert.Equal(t, 1, bot.sendReminders(poll, users))
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestHandleRemindSendsInBackground(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{PollID: "test-poll", CreatorID: "creator-user", Question: "Test question?", Status: "active", ChannelID: "test-channel"}
+	mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+	mockTarantool.On("GetVoters", context.Background(), "test-poll").Return([]string{"creator-user"}, nil)
+	mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(model.ChannelMembers{
+		{UserId: "creator-user"},
+		{UserId: "lazy-user"},
+	}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "Отправляю напоминания: 1"
+	})).Return(&model.Post{}, &model.Response{}, nil)
+	mockMM.On("CreateDirectChannel", context.Background(), "bot-user", "lazy-user").Return(&model.Channel{Id: "dm-channel"}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "dm-channel"
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+	}
+	bot.handleRemind(&model.Post{UserId: "creator-user", ChannelId: "test-channel"}, []string{"test-poll"})
+
+	// Рассылку, как и команды в очередях, дожидается остановка бота
+	bot.background.Wait()
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestProcessReminders(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	now := time.Unix(1000, 0)
+	poll := &tarantool.Poll{PollID: "test-poll", Question: "Test question?", Status: "active", ChannelID: "test-channel"}
+
+	mockTarantool.On("GetDueReminders", context.Background(), int64(1000)).Return([]tarantool.Reminder{
+		{PollID: "test-poll", RemindAt: 900},
+		{PollID: "other-poll", RemindAt: 950},
+	}, nil)
+	// Второе напоминание уже забрала другая реплика
+	mockTarantool.On("ClaimReminder", context.Background(), "test-poll", int64(900)).Return(true, nil)
+	mockTarantool.On("ClaimReminder", context.Background(), "other-poll", int64(950)).Return(false, nil)
+	mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+	mockTarantool.On("GetVoters", context.Background(), "test-poll").Return([]string{}, nil)
+	mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(model.ChannelMembers{
+		{UserId: "lazy-user"},
+	}, &model.Response{}, nil)
+	mockMM.On("CreateDirectChannel", context.Background(), "bot-user", "lazy-user").Return(&model.Channel{Id: "dm-channel"}, &model.Response{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "dm-channel"
+	})).Return(&model.Post{}, &model.Response{}, nil).Once()
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+	}
+	bot.processReminders(now)
+	// Рассылка идёт в фоне и не задерживает планировщик
+	bot.background.Wait()
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"voting-bot/tarantool"
+)
+
+// formatPollResults готовит текст результатов голосования любого типа.
+func (b *Bot) formatPollResults(poll *tarantool.Poll) (string, error) {
+	switch poll.Type {
+	case tarantool.PollText:
+		return b.formatAnswers(poll)
+	case tarantool.PollSchedule:
+		return b.formatSchedule(poll, b.userLocation(poll.CreatorID))
+	}
+
+	results, err := b.pollResults(poll)
+	if err != nil {
+		return "", err
+	}
+	return formatResults(results), nil
+}
+
+func formatResults(results *tarantool.VoteResult) string {
+	response := fmt.Sprintf("**Результаты голосования**: %s\n", results.Question)
+	if results.Type == tarantool.PollQuiz && results.Status == "active" {
+		return response + fmt.Sprintf("Ответов: %d. Результаты викторины откроются после завершения", results.Total)
+	}
+
+	shares := resultShares(results)
+	for i, opt := range results.Options {
+		switch results.Type {
+		case tarantool.PollScore:
+			response += fmt.Sprintf("%d. %s - %s", i+1, opt, formatScore(results.Scores[i]))
+			if results.Weighted != nil {
+				response += fmt.Sprintf(" (средняя с учётом весов: %s)", formatWeight(results.Weighted[i]))
+			}
+		case tarantool.PollApproval:
+			response += fmt.Sprintf("%d. %s - %d одобрений", i+1, opt, results.Votes[i])
+			if results.Weighted != nil {
+				response += fmt.Sprintf(" (с учётом весов: %s)", formatWeight(results.Weighted[i]))
+			}
+		default:
+			response += fmt.Sprintf("%d. %s - %d голосов", i+1, opt, results.Votes[i])
+			if results.Weighted != nil {
+				response += fmt.Sprintf(" (с учётом весов: %s)", formatWeight(results.Weighted[i]))
+			}
+			if slices.Contains(results.Correct, i+1) {
+				response += " ✅"
+			}
+		}
+		if shares != nil {
+			response += "\n" + resultBar(shares[i])
+		}
+		response += "\n"
+	}
+
+	if results.Type == tarantool.PollApproval || results.Type == tarantool.PollScore {
+		response += fmt.Sprintf("\nВсего проголосовавших: %d", results.Total)
+	} else {
+		response += fmt.Sprintf("\nВсего голосов: %d", results.Total)
+	}
+	if results.Weighted != nil {
+		response += fmt.Sprintf(", с учётом весов: %s", formatWeight(results.WeightedTotal))
+	}
+	if results.Type == tarantool.PollQuiz {
+		response += fmt.Sprintf("\nПравильных ответов: %d", results.CorrectVotes)
+	}
+	if results.Delegated > 0 {
+		response += fmt.Sprintf("\nИз них по доверенности: %d", results.Delegated)
+	}
+
+	if results.Quorum > 0 {
+		if results.Eligible > 0 {
+			response += fmt.Sprintf("\nЯвка: %d из %d (%d%%)", results.Total, results.Eligible, results.Total*100/results.Eligible)
+		}
+		if results.QuorumReached() {
+			response += fmt.Sprintf("\nКворум: %d — достигнут", results.Quorum)
+		} else {
+			response += fmt.Sprintf("\nКворум: %d — не достигнут", results.Quorum)
+			if results.Status == "closed" {
+				response += "\n**Итог: кворум не достигнут, решение не принято**"
+			}
+		}
+	}
+
+	if results.Type != tarantool.PollQuiz && results.QuorumReached() {
+		if outcome := formatOutcome(results); outcome != "" {
+			response += "\n" + outcome
+		}
+	}
+	return response
+}
+
+// formatOutcome называет победителя с отрывом от второго места или
+// варианты, разделившие первое место.
+func formatOutcome(results *tarantool.VoteResult) string {
+	if len(results.Winners) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(results.Winners))
+	for i, optionNum := range results.Winners {
+		names[i] = results.Options[optionNum-1]
+	}
+
+	if results.Tie {
+		last := len(names) - 1
+		return fmt.Sprintf("Ничья между %s и %s", strings.Join(names[:last], ", "), names[last])
+	}
+
+	outcome := "Победитель: " + names[0]
+	switch {
+	case results.Margin == 0:
+	case results.Type == tarantool.PollScore:
+		outcome += fmt.Sprintf(" (отрыв по средней оценке: %s)", formatWeight(results.Margin))
+	case results.Weighted != nil:
+		outcome += fmt.Sprintf(" (отрыв с учётом весов: %s)", formatWeight(results.Margin))
+	default:
+		outcome += fmt.Sprintf(" (отрыв: %s голосов)", formatWeight(results.Margin))
+	}
+	return outcome
+}
+
+// formatScore выводит среднюю, медиану и распределение оценок варианта.
+func formatScore(stats tarantool.ScoreStats) string {
+	if stats.Count == 0 {
+		return "нет оценок"
+	}
+
+	scores := make([]int, 0, len(stats.Distribution))
+	for score := range stats.Distribution {
+		scores = append(scores, score)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+
+	distribution := make([]string, len(scores))
+	for i, score := range scores {
+		distribution[i] = fmt.Sprintf("%d: %d", score, stats.Distribution[score])
+	}
+
+	return fmt.Sprintf("средняя %s, медиана %s, оценок %d (%s)",
+		strconv.FormatFloat(stats.Average, 'f', 2, 64),
+		strconv.FormatFloat(stats.Median, 'f', -1, 64),
+		stats.Count,
+		strings.Join(distribution, ", "))
+}
@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // в финальном образе нет базы часовых поясов
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const (
+	defaultSlotDuration = time.Hour
+	slotLayout          = time.RFC3339
+)
+
+var (
+	weekdays   = [...]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+	dateLayout = []string{"2006-01-02", "02.01.2006"}
+	errSlot    = errors.New("invalid time slot")
+)
+
+// timeSlot — вариант голосования PollSchedule. В опциях голосования слот
+// хранится в UTC в виде "начало/конец" (RFC 3339).
+type timeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (s timeSlot) option() string {
+	return s.Start.UTC().Format(slotLayout) + "/" + s.End.UTC().Format(slotLayout)
+}
+
+func slotFromOption(option string) (timeSlot, error) {
+	startPart, endPart, ok := strings.Cut(option, "/")
+	if !ok {
+		return timeSlot{}, errSlot
+	}
+
+	start, err := time.Parse(slotLayout, startPart)
+	if err != nil {
+		return timeSlot{}, errSlot
+	}
+	end, err := time.Parse(slotLayout, endPart)
+	if err != nil || !end.After(start) {
+		return timeSlot{}, errSlot
+	}
+	return timeSlot{Start: start, End: end}, nil
+}
+
+// format выводит слот в часовом поясе loc: "Вт 03.11 10:00–12:00".
+func (s timeSlot) format(loc *time.Location) string {
+	start, end := s.Start.In(loc), s.End.In(loc)
+	label := fmt.Sprintf("%s %s–", weekdays[start.Weekday()], start.Format("02.01 15:04"))
+	if end.YearDay() != start.YearDay() || end.Year() != start.Year() {
+		return label + fmt.Sprintf("%s %s", weekdays[end.Weekday()], end.Format("02.01 15:04"))
+	}
+	return label + end.Format("15:04")
+}
+
+// parseSlots разбирает слоты вида "2026-11-03 10:00-12:00 14:00 2026-11-04 9:30-10:00":
+// дата относится ко всем следующим за ней интервалам, время без конца
+// означает слот длительностью defaultSlotDuration.
+func parseSlots(args []string, loc *time.Location) ([]timeSlot, error) {
+	var (
+		day   time.Time
+		slots []timeSlot
+	)
+	for _, arg := range args {
+		if date, ok := parseDate(arg, loc); ok {
+			day = date
+			continue
+		}
+
+		if day.IsZero() {
+			return nil, errSlot
+		}
+
+		startPart, endPart, hasEnd := strings.Cut(strings.ReplaceAll(arg, "–", "-"), "-")
+		start, err := clockOn(day, startPart)
+		if err != nil {
+			return nil, err
+		}
+
+		end := start.Add(defaultSlotDuration)
+		if hasEnd {
+			if end, err = clockOn(day, endPart); err != nil {
+				return nil, err
+			}
+		}
+		if !end.After(start) {
+			return nil, errSlot
+		}
+		slots = append(slots, timeSlot{Start: start, End: end})
+	}
+
+	if len(slots) == 0 {
+		return nil, errSlot
+	}
+	return slots, nil
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dateLayout {
+		if date, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clockOn возвращает момент времени "15:04" в день day.
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, errSlot
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+func (b *Bot) handleSchedule(post *model.Post, args []string) {
+	flags, args := splitFlagArgs(args)
+	if len(args) < 3 {
+		b.sendReply(post.ChannelId, "Использование: /schedule [--deadline=24h] \"Тема встречи\" 2026-11-03 10:00-12:00 14:00-15:00 [2026-11-04 10:00 ...]")
+		return
+	}
+
+	slots, err := parseSlots(args[1:], b.userLocation(post.UserId))
+	if err != nil {
+		b.sendReply(post.ChannelId, "Не удалось разобрать слоты: укажите дату (2026-11-03 или 03.11.2026), затем интервалы 10:00-12:00 или время начала 14:00")
+		return
+	}
+
+	createArgs := append(flags, "--type="+tarantool.PollSchedule, args[0])
+	for _, slot := range slots {
+		createArgs = append(createArgs, slot.option())
+	}
+	b.handleCreatePoll(post, createArgs)
+}
+
+// userLocation возвращает часовой пояс из профиля Mattermost, по умолчанию UTC.
+func (b *Bot) userLocation(userID string) *time.Location {
+	user, _, err := b.Client.GetUser(b.ctx(), userID, "")
+	if err != nil {
+		b.logger().Error("Ошибка получения пользователя", "user_id", userID, "err", err)
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(user.GetPreferredTimezone())
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// formatSlots выводит варианты голосования PollSchedule в часовом поясе loc.
+func formatSlots(options []string, loc *time.Location) []string {
+	labels := make([]string, len(options))
+	for i, option := range options {
+		labels[i] = option
+		if slot, err := slotFromOption(option); err == nil {
+			labels[i] = slot.format(loc)
+		}
+	}
+	return labels
+}
+
+// formatSchedule выводит таблицу доступности участников по слотам и лучший
+// слот: с наибольшим числом "да", при равенстве — "да" и "если нужно".
+// В анонимном голосовании строки участников не выводятся, только итоги.
+func (b *Bot) formatSchedule(poll *tarantool.Poll, loc *time.Location) (string, error) {
+	results, err := b.TarantoolClient.GetResults(b.ctx(), poll.PollID)
+	if err != nil {
+		return "", err
+	}
+
+	labels := formatSlots(results.Options, loc)
+	response := fmt.Sprintf("**Выбор времени**: %s\nЧасовой пояс: %s\n", results.Question, loc)
+	if results.Total == 0 {
+		return response + "Пока никто не отметился", nil
+	}
+
+	best, bestYes, bestMaybe := -1, 0, 0
+	for i, stats := range results.Scores {
+		yes := stats.Distribution[tarantool.AvailabilityYes]
+		maybe := stats.Distribution[tarantool.AvailabilityMaybe]
+		if yes > bestYes || (yes == bestYes && yes+maybe > bestYes+bestMaybe) {
+			best, bestYes, bestMaybe = i, yes, maybe
+		}
+	}
+
+	response += "\n| Участник |"
+	for i, label := range labels {
+		if i == best {
+			label = "⭐ " + label
+		}
+		response += " " + label + " |"
+	}
+	response += "\n|---|" + strings.Repeat(":-:|", len(labels)) + "\n"
+
+	if !poll.Anonymous {
+		rows, err := b.scheduleRows(poll, results, len(labels))
+		if err != nil {
+			return "", err
+		}
+		response += rows
+	}
+
+	response += "| **Итого** |"
+	for _, stats := range results.Scores {
+		response += fmt.Sprintf(" ✅ %d ❔ %d |", stats.Distribution[tarantool.AvailabilityYes], stats.Distribution[tarantool.AvailabilityMaybe])
+	}
+	response += "\n"
+
+	if best >= 0 {
+		response += fmt.Sprintf("\n**Лучший слот**: %s — да: %d, если нужно: %d", labels[best], bestYes, bestMaybe)
+	} else {
+		response += "\nНи один слот не подходит"
+	}
+	return response, nil
+}
+
+// scheduleRows выводит строки таблицы доступности по участникам.
+func (b *Bot) scheduleRows(poll *tarantool.Poll, results *tarantool.VoteResult, slots int) (string, error) {
+	userIDs := make([]string, len(results.Ballots))
+	for i, vote := range results.Ballots {
+		userIDs[i] = vote.UserID
+	}
+	users, _, err := b.Client.GetUsersByIds(b.ctx(), userIDs)
+	if err != nil {
+		return "", err
+	}
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		usernames[user.Id] = "@" + user.Username
+	}
+
+	var rows string
+	for _, vote := range results.Ballots {
+		ballot, err := poll.DecodeBallot(vote.Option)
+		if err != nil {
+			continue
+		}
+
+		name, ok := usernames[vote.UserID]
+		if !ok {
+			name = vote.UserID
+		}
+		rows += "| " + name + " |"
+		for i := range slots {
+			availability, answered := ballot[i+1]
+			rows += " " + availabilityMark(availability, answered) + " |"
+		}
+		rows += "\n"
+	}
+	return rows, nil
+}
+
+func availabilityMark(availability int, answered bool) string {
+	switch {
+	case !answered:
+		return "—"
+	case availability == tarantool.AvailabilityYes:
+		return "✅"
+	case availability == tarantool.AvailabilityMaybe:
+		return "❔"
+	}
+	return "❌"
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"voting-bot/tarantool"
+)
+
+func TestParseSlots(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "ranges on one day",
+			args: []string{"2026-11-03", "10:00-12:00", "14:00–15:00"},
+			want: []string{"2026-11-03T07:00:00Z/2026-11-03T09:00:00Z", "2026-11-03T11:00:00Z/2026-11-03T12:00:00Z"},
+		},
+		{
+			name: "several days and default duration",
+			args: []string{"03.11.2026", "9:30", "2026-11-04", "18:00-19:30"},
+			want: []string{"2026-11-03T06:30:00Z/2026-11-03T07:30:00Z", "2026-11-04T15:00:00Z/2026-11-04T16:30:00Z"},
+		},
+		{name: "time before date", args: []string{"10:00-11:00"}, wantErr: true},
+		{name: "end before start", args: []string{"2026-11-03", "12:00-10:00"}, wantErr: true},
+		{name: "only date", args: []string{"2026-11-03"}, wantErr: true},
+		{name: "garbage", args: []string{"2026-11-03", "утром"}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slots, err := parseSlots(tc.args, moscow)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			var got []string
+			for _, slot := range slots {
+				got = append(got, slot.option())
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSlotFormat(t *testing.T) {
+	slot, err := slotFromOption("2026-11-03T07:00:00Z/2026-11-03T09:00:00Z")
+	require.NoError(t, err)
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	assert.Equal(t, "Вт 03.11 10:00–12:00", slot.format(moscow))
+
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	assert.Equal(t, "Пн 02.11 23:00–Вт 03.11 01:00", slot.format(losAngeles))
+}
+
+func TestHandleSchedule(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	creator := &model.User{
+		Id:       "test-user",
+		Timezone: model.StringMap{"useAutomaticTimezone": "false", "manualTimezone": "Europe/Moscow"},
+	}
+	mockMM.On("GetUser", context.Background(), "test-user", "").Return(creator, &model.Response{}, nil)
+	mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(poll *tarantool.Poll) bool {
+		return poll.Type == tarantool.PollSchedule &&
+			poll.Question == "Ретро" &&
+			poll.Deadline > 0 &&
+			assert.ObjectsAreEqual([]string{
+				"2026-11-03T07:00:00Z/2026-11-03T09:00:00Z",
+				"2026-11-03T11:00:00Z/2026-11-03T12:00:00Z",
+			}, poll.Options)
+	})).Return(nil)
+	mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return strings.Contains(post.Message, "**Часовой пояс**: Europe/Moscow") &&
+			strings.Contains(post.Message, "1. Вт 03.11 10:00–12:00") &&
+			strings.Contains(post.Message, "2. Вт 03.11 14:00–15:00")
+	})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	message := `/schedule --deadline=24h "Ретро" 2026-11-03 10:00-12:00 14:00-15:00`
+	post := &model.Post{
+		UserId:    "test-user",
+		ChannelId: "test-channel",
+		Message:   message,
+	}
+
+	bot.handleSchedule(post, splitArgs(message)[1:])
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestFormatSchedule(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:   "schedule-poll",
+		Question: "Ретро",
+		Type:     tarantool.PollSchedule,
+		Options: []string{
+			"2026-11-03T07:00:00Z/2026-11-03T09:00:00Z",
+			"2026-11-03T11:00:00Z/2026-11-03T12:00:00Z",
+		},
+	}
+
+	mockTarantool.On("GetResults", context.Background(), "schedule-poll").Return(&tarantool.VoteResult{
+		Question: "Ретро",
+		Type:     tarantool.PollSchedule,
+		Options:  poll.Options,
+		Ballots: []tarantool.Vote{
+			{UserID: "u1", Option: "1=2,2=2"},
+			{UserID: "u2", Option: "1=0,2=1"},
+			{UserID: "u3", Option: "2=2"},
+		},
+		Total: 3,
+		Scores: []tarantool.ScoreStats{
+			{Count: 2, Distribution: map[int]int{0: 1, 2: 1}},
+			{Count: 3, Distribution: map[int]int{1: 1, 2: 2}},
+		},
+	}, nil)
+	mockMM.On("GetUsersByIds", context.Background(), []string{"u1", "u2", "u3"}).Return([]*model.User{
+		{Id: "u1", Username: "alice"},
+		{Id: "u2", Username: "bob"},
+		{Id: "u3", Username: "carol"},
+	}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	response, err := bot.formatSchedule(poll, time.UTC)
+	require.NoError(t, err)
+	assert.Contains(t, response, "| Участник | Вт 03.11 07:00–09:00 | ⭐ Вт 03.11 11:00–12:00 |")
+	assert.Contains(t, response, "| @bob | ❌ | ❔ |")
+	assert.Contains(t, response, "| @carol | — | ✅ |")
+	assert.Contains(t, response, "| **Итого** | ✅ 1 ❔ 0 | ✅ 2 ❔ 1 |")
+	assert.Contains(t, response, "**Лучший слот**: Вт 03.11 11:00–12:00 — да: 2, если нужно: 1")
+}
+
+func TestFormatScheduleAnonymous(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "schedule-poll",
+		Question:  "Ретро",
+		Type:      tarantool.PollSchedule,
+		Anonymous: true,
+		Options:   []string{"2026-11-03T07:00:00Z/2026-11-03T09:00:00Z"},
+	}
+
+	mockTarantool.On("GetResults", context.Background(), "schedule-poll").Return(&tarantool.VoteResult{
+		Question: "Ретро",
+		Type:     tarantool.PollSchedule,
+		Options:  poll.Options,
+		Ballots:  []tarantool.Vote{{UserID: "u1", Option: "1=2"}},
+		Total:    1,
+		Scores:   []tarantool.ScoreStats{{Count: 1, Distribution: map[int]int{2: 1}}},
+	}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	response, err := bot.formatSchedule(poll, time.UTC)
+	require.NoError(t, err)
+	assert.NotContains(t, response, "u1")
+	assert.Contains(t, response, "| **Итого** | ✅ 1 ❔ 0 |")
+	// Имена участников не запрашиваются
+	mockMM.AssertNotCalled(t, "GetUsersByIds", mock.Anything, mock.Anything)
+}
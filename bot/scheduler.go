@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"voting-bot/tarantool"
+)
+
+const schedulerInterval = time.Minute
+
+// runScheduler периодически выполняет отложенные задачи бота, пока не
+// отменён ctx.
+func (b *Bot) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b.runScheduledTasks(now)
+		}
+	}
+}
+
+func (b *Bot) runScheduledTasks(now time.Time) {
+	defer b.logPanic("планировщик", now)
+	b.processReminders(now)
+	b.processDeadlines(now)
+	b.processRecurrences(now)
+}
+
+// processDeadlines закрывает голосования с истёкшим сроком и публикует итоги
+// тех, что удалось закрыть.
+func (b *Bot) processDeadlines(now time.Time) {
+	polls, err := b.TarantoolClient.GetExpiredPolls(b.ctx(), now.Unix())
+	if err != nil {
+		b.logger().Error("Ошибка получения просроченных голосований", "err", err)
+		return
+	}
+
+	for _, poll := range polls {
+		closed, err := b.TarantoolClient.ClosePoll(b.ctx(), poll.PollID)
+		if err != nil {
+			b.logger().Error("Ошибка завершения голосования", "poll_id", poll.PollID, "err", err)
+			continue
+		}
+		// Голосование уже закрыто через /endpoll или другой репликой
+		if !closed {
+			continue
+		}
+		b.metrics.PollClosed()
+
+		if poll.Type == tarantool.PollQuiz {
+			b.finishQuiz(poll)
+		}
+
+		b.announceFinalResults(poll, poll.ChannelID, "Голосование завершено по истечении срока.")
+	}
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestProcessDeadlines(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	now := time.Now()
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		Status:    "active",
+		ChannelID: "test-channel",
+		Deadline:  now.Add(-time.Minute).Unix(),
+		Quorum:    3,
+	}
+
+	mockTarantool.On("GetExpiredPolls", context.Background(), now.Unix()).Return([]*tarantool.Poll{poll}, nil)
+	mockTarantool.On("ClosePoll", context.Background(), "test-poll").Return(true, nil)
+	mockTarantool.On("GetResults", context.Background(), "test-poll").Return(&tarantool.VoteResult{
+		Question: "Test question?",
+		Status:   "closed",
+		Options:  []string{"A", "B"},
+		Votes:    []int{1, 0},
+		Total:    1,
+		Quorum:   3,
+	}, nil)
+	mockTarantool.On("GetWeights", context.Background(), "test-channel").Return([]tarantool.Weight{}, nil)
+	mockTarantool.On("GetWeights", context.Background(), "test-poll").Return([]tarantool.Weight{}, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "test-channel" &&
+			strings.Contains(post.Message, "завершено по истечении срока") &&
+			strings.Contains(post.Message, "кворум не достигнут")
+	})).Return(&model.Post{Id: "results-post"}, &model.Response{}, nil)
+	mockTarantool.On("SaveFinalResult", context.Background(), mock.MatchedBy(func(result tarantool.FinalResult) bool {
+		return result.PollID == "test-poll" && result.PostID == "results-post"
+	})).Return(nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.processDeadlines(now)
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestProcessDeadlinesSkipsClosedPolls(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	now := time.Now()
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		Status:    "active",
+		ChannelID: "test-channel",
+		Deadline:  now.Add(-time.Minute).Unix(),
+	}
+
+	// Голосование закрыли через /endpoll между выборкой и закрытием
+	mockTarantool.On("GetExpiredPolls", context.Background(), now.Unix()).Return([]*tarantool.Poll{poll}, nil)
+	mockTarantool.On("ClosePoll", context.Background(), "test-poll").Return(false, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	bot.processDeadlines(now)
+
+	mockTarantool.AssertExpectations(t)
+	mockTarantool.AssertNotCalled(t, "GetResults", mock.Anything, mock.Anything)
+	mockMM.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+}
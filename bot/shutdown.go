@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ctx возвращает контекст для обращений к Mattermost и Tarantool из
+// обработчиков. Он отменяется, только если при остановке начатые команды
+// не уложились в ShutdownTimeout.
+func (b *Bot) ctx() context.Context {
+	if b.handlerCtx == nil {
+		return context.Background()
+	}
+	return b.handlerCtx
+}
+
+// Listen обрабатывает события Mattermost и запускает планировщик, пока не
+// отменён ctx. После отмены бот перестаёт принимать события, ждёт
+// выполнения принятых команд и начатых ими рассылок не дольше
+// ShutdownTimeout и закрывает websocket-соединение. Tarantool закрывает вызывающий после возврата.
+func (b *Bot) Listen(ctx context.Context) error {
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+	b.handlerCtx = handlerCtx
+	b.beat()
+	defer b.heartbeat.Store(0)
+
+	b.pool = newWorkerPool(b.Workers, b.QueueSize, b.logger(), b.metrics)
+	intakeCtx, stopIntake := context.WithCancel(ctx)
+	defer stopIntake()
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 1)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		b.runScheduler(intakeCtx)
+	}()
+	go func() {
+		defer wg.Done()
+		errc <- b.run(intakeCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		b.logger().Info("Остановка бота: ожидание завершения начатых команд")
+	}
+	stopIntake()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		b.pool.stop()
+		b.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(b.ShutdownTimeout):
+		b.logger().Warn("Команды не завершились вовремя, обработка прервана", "timeout", b.ShutdownTimeout)
+		cancelHandlers()
+	}
+
+	b.conn.closeStream()
+	if err == nil {
+		b.conn.set(false, 0, nil)
+	}
+	return err
+}
+
+// goBackground запускает задачу, начатую командой, но выполняемую вне
+// очереди (например, рассылку напоминаний). Listen при остановке дожидается
+// её так же, как команд в очередях.
+func (b *Bot) goBackground(what string, task func()) {
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		defer b.logPanic(what, nil)
+		task()
+	}()
+}
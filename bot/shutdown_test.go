@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func postedEvent(t *testing.T, post *model.Post) *model.WebSocketEvent {
+	data, err := json.Marshal(post)
+	require.NoError(t, err)
+
+	event := model.NewWebSocketEvent(model.WebsocketEventPosted, "", post.ChannelId, "", nil, "")
+	event.Add("post", string(data))
+	return event
+}
+
+func TestListenShutdown(t *testing.T) {
+	tests := []struct {
+		name            string
+		shutdownTimeout time.Duration
+		// reply имитирует отправку ответа на команду
+		reply         func(ctx context.Context, release chan struct{})
+		wantCancelled bool
+	}{
+		{
+			name:            "drains in-flight command",
+			shutdownTimeout: time.Second,
+			reply: func(ctx context.Context, release chan struct{}) {
+				<-release
+			},
+		},
+		{
+			name:            "cancels command after timeout",
+			shutdownTimeout: 20 * time.Millisecond,
+			reply: func(ctx context.Context, release chan struct{}) {
+				<-ctx.Done()
+			},
+			wantCancelled: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTarantool := new(MockTarantool)
+			mockMM := new(MockMattermostClient)
+
+			stream := newFakeStream()
+			stream.events <- postedEvent(t, &model.Post{Id: "post", ChannelId: "test-channel", UserId: "user", Message: "/results"})
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var replyCtx context.Context
+
+			mockMM.On("GetMe", mock.Anything, "").Return(&model.User{Id: "bot-user"}, &model.Response{}, nil)
+			mockMM.On("GetTeamsForUser", mock.Anything, "bot-user", "").Return([]*model.Team{}, &model.Response{}, nil)
+			mockTarantool.On("ClaimPost", mock.Anything, "post", "test-channel", int64(0)).Return(true, nil)
+			mockMM.On("CreatePost", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				replyCtx = args.Get(0).(context.Context)
+				close(started)
+				tc.reply(replyCtx, release)
+			}).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+				UserID:          "bot-user",
+				ShutdownTimeout: tc.shutdownTimeout,
+				dial: func() (eventStream, error) {
+					return stream, nil
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			result := make(chan error, 1)
+			go func() {
+				result <- bot.Listen(ctx)
+			}()
+
+			<-started
+			cancel()
+			// Пока команда выполняется, соединение остаётся открытым
+			time.Sleep(10 * time.Millisecond)
+			assert.False(t, stream.closed.Load())
+			close(release)
+
+			select {
+			case err := <-result:
+				assert.NoError(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("Listen did not return after shutdown")
+			}
+
+			assert.True(t, stream.closed.Load())
+			assert.False(t, bot.ConnectionState().Connected)
+			if tc.wantCancelled {
+				assert.ErrorIs(t, replyCtx.Err(), context.Canceled)
+			}
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const templateUsage = "Использование:\n" +
+	"/template save ИМЯ ID_ГОЛОСОВАНИЯ\n" +
+	"/template use ИМЯ\n" +
+	"/template list"
+
+func (b *Bot) handleTemplate(post *model.Post, args []string) {
+	if len(args) < 1 {
+		b.sendReply(post.ChannelId, templateUsage)
+		return
+	}
+
+	switch action := args[0]; {
+	case action == "save" && len(args) == 3:
+		b.saveTemplate(post, args[1], args[2])
+	case action == "use" && len(args) == 2:
+		b.useTemplate(post, args[1])
+	case action == "list" && len(args) == 1:
+		b.listTemplates(post)
+	default:
+		b.sendReply(post.ChannelId, templateUsage)
+	}
+}
+
+func (b *Bot) saveTemplate(post *model.Post, name, pollID string) {
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), pollID)
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	if !b.memberOfPollChannel(poll, post.UserId) {
+		b.sendReply(post.ChannelId, "Сохранить шаблоном можно только голосование из канала, участником которого вы являетесь")
+		return
+	}
+
+	if poll.Type == tarantool.PollSchedule {
+		b.sendReply(post.ChannelId, "Выбор времени привязан к датам, сохранить его шаблоном нельзя")
+		return
+	}
+
+	existing, err := b.TarantoolClient.GetTemplate(b.ctx(), name)
+	if err != nil && !errors.Is(err, tarantool.ErrNotFound) {
+		b.postLog(post).Error("Ошибка получения шаблона", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось сохранить шаблон")
+		return
+	}
+	if existing != nil && existing.CreatorID != post.UserId {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Шаблон %s уже есть, изменить его может только автор", name))
+		return
+	}
+
+	snapshot := *poll
+	snapshot.Status = ""
+	snapshot.PostID = ""
+	err = b.TarantoolClient.SaveTemplate(b.ctx(), tarantool.Template{
+		Name:      name,
+		CreatorID: post.UserId,
+		Poll:      &snapshot,
+	})
+	if err != nil {
+		b.postLog(post).Error("Ошибка сохранения шаблона", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось сохранить шаблон")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Шаблон %s сохранён, запуск: `/template use %s`", name, name))
+}
+
+func (b *Bot) useTemplate(post *model.Post, name string) {
+	template, err := b.TarantoolClient.GetTemplate(b.ctx(), name)
+	if errors.Is(err, tarantool.ErrNotFound) {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Шаблон %s не найден", name))
+		return
+	}
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения шаблона", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить шаблон")
+		return
+	}
+
+	b.launchCopy(post, template.Poll)
+}
+
+// listTemplates показывает только шаблоны автора команды: вопросы чужих
+// шаблонов взяты из каналов, которые ему могут быть не видны.
+func (b *Bot) listTemplates(post *model.Post) {
+	all, err := b.TarantoolClient.GetTemplates(b.ctx())
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения шаблонов", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить шаблоны")
+		return
+	}
+
+	var templates []tarantool.Template
+	for _, template := range all {
+		if template.CreatorID == post.UserId {
+			templates = append(templates, template)
+		}
+	}
+
+	if len(templates) == 0 {
+		b.sendReply(post.ChannelId, "У вас пока нет шаблонов: `/template save ИМЯ ID_ГОЛОСОВАНИЯ`")
+		return
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	response := "**Ваши шаблоны голосований**:\n"
+	for _, template := range templates {
+		response += fmt.Sprintf("- `%s` — %s (%s)\n", template.Name, template.Poll.Question, template.Poll.Type)
+	}
+	b.sendReply(post.ChannelId, response)
+}
+
+func (b *Bot) handleClonePoll(post *model.Post, args []string) {
+	if len(args) != 1 {
+		b.sendReply(post.ChannelId, "Использование: /clonepoll ID_ГОЛОСОВАНИЯ")
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	if !b.memberOfPollChannel(poll, post.UserId) {
+		b.sendReply(post.ChannelId, "Клонировать можно только голосование из канала, участником которого вы являетесь")
+		return
+	}
+
+	b.launchCopy(post, poll)
+}
+
+// memberOfPollChannel проверяет, что userID состоит в канале голосования:
+// вопрос, варианты и голоса видны только участникам канала.
+func (b *Bot) memberOfPollChannel(poll *tarantool.Poll, userID string) bool {
+	_, _, err := b.Client.GetChannelMember(b.ctx(), poll.ChannelID, userID, "")
+	return err == nil
+}
+
+// launchCopy запускает в текущем канале новое голосование с вопросом,
+// вариантами и настройками source. Срок отсчитывается заново (у голосований,
+// созданных до учёта времени создания, он не переносится), число
+// участников берётся из нового канала, а кворум в процентах пересчитывается
+// от него; кворум, заданный числом голосов, копируется как есть.
+func (b *Bot) launchCopy(post *model.Post, source *tarantool.Poll) {
+	now := time.Now()
+	poll := *source
+	poll.PollID = model.NewId()
+	poll.CreatorID = post.UserId
+	poll.ChannelID = post.ChannelId
+	poll.PostID = ""
+	poll.CreatedAt = now.Unix()
+	poll.Deadline = 0
+	if source.Deadline != 0 && source.CreatedAt != 0 {
+		poll.Deadline = now.Unix() + source.Deadline - source.CreatedAt
+	}
+
+	if poll.Quorum > 0 || poll.QuorumPercent > 0 {
+		members, err := b.channelMembers(post.ChannelId)
+		if err != nil {
+			b.postLog(post).Error("Ошибка получения участников канала", "err", err)
+			b.sendReply(post.ChannelId, "Не удалось получить список участников канала")
+			return
+		}
+		poll.Eligible = len(members)
+		if poll.QuorumPercent > 0 {
+			poll.Quorum = quorumOf(poll.Eligible, poll.QuorumPercent)
+		}
+	}
+
+	b.publishPoll(&poll)
+}
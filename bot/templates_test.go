@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleTemplate(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "lunch-poll",
+		CreatorID: "creator-user",
+		Question:  "Где обедаем?",
+		Options:   []string{"Кафе", "Столовая"},
+		Status:    "closed",
+		ChannelID: "other-channel",
+		PostID:    "old-post",
+		CreatedAt: 1000,
+		Deadline:  1000 + 3600,
+		Type:      tarantool.PollApproval,
+	}
+	snapshot := *poll
+	snapshot.Status = ""
+	snapshot.PostID = ""
+	template := &tarantool.Template{Name: "lunch", CreatorID: "creator-user", Poll: &snapshot}
+
+	tests := []struct {
+		name       string
+		userID     string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:   "save",
+			userID: "creator-user",
+			args:   []string{"save", "lunch", "lunch-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "lunch-poll").Return(poll, nil)
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "creator-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockTarantool.On("GetTemplate", context.Background(), "lunch").Return(nil, tarantool.ErrNotFound)
+				mockTarantool.On("SaveTemplate", context.Background(), *template).Return(nil)
+			},
+			reply: "Шаблон lunch сохранён",
+		},
+		{
+			name:   "save from foreign channel",
+			userID: "outsider",
+			args:   []string{"save", "lunch", "lunch-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "lunch-poll").Return(poll, nil)
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "outsider", "").Return((*model.ChannelMember)(nil), &model.Response{StatusCode: 404}, errors.New("not found"))
+			},
+			reply: "Сохранить шаблоном можно только голосование из канала",
+		},
+		{
+			name:   "overwrite foreign template",
+			userID: "other-user",
+			args:   []string{"save", "lunch", "lunch-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "lunch-poll").Return(poll, nil)
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "other-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockTarantool.On("GetTemplate", context.Background(), "lunch").Return(template, nil)
+			},
+			reply: "изменить его может только автор",
+		},
+		{
+			name:   "use",
+			userID: "other-user",
+			args:   []string{"use", "lunch"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplate", context.Background(), "lunch").Return(template, nil)
+				mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(created *tarantool.Poll) bool {
+					return created.PollID != "lunch-poll" &&
+						created.CreatorID == "other-user" &&
+						created.ChannelID == "test-channel" &&
+						created.Type == tarantool.PollApproval &&
+						created.Deadline-created.CreatedAt == 3600 &&
+						created.CreatedAt >= time.Now().Add(-time.Minute).Unix() &&
+						assert.ObjectsAreEqual(poll.Options, created.Options)
+				})).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+			},
+			reply: "Голосование создано!",
+		},
+		{
+			name:   "use legacy poll without creation time",
+			userID: "other-user",
+			args:   []string{"use", "legacy"},
+			setupMocks: func() {
+				legacy := snapshot
+				legacy.CreatedAt = 0
+				mockTarantool.On("GetTemplate", context.Background(), "legacy").Return(&tarantool.Template{Name: "legacy", CreatorID: "creator-user", Poll: &legacy}, nil)
+				// Срок без времени создания не переносится
+				mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(created *tarantool.Poll) bool {
+					return created.Deadline == 0
+				})).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+			},
+			reply: "Голосование создано!",
+		},
+		{
+			name:   "use unknown",
+			userID: "other-user",
+			args:   []string{"use", "standup"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplate", context.Background(), "standup").Return(nil, tarantool.ErrNotFound)
+			},
+			reply: "Шаблон standup не найден",
+		},
+		{
+			name:   "list",
+			userID: "creator-user",
+			args:   []string{"list"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplates", context.Background()).Return([]tarantool.Template{*template}, nil)
+			},
+			reply: "- `lunch` — Где обедаем? (approval)",
+		},
+		{
+			name:   "list hides foreign templates",
+			userID: "other-user",
+			args:   []string{"list"},
+			setupMocks: func() {
+				mockTarantool.On("GetTemplates", context.Background()).Return([]tarantool.Template{*template}, nil)
+			},
+			reply: "У вас пока нет шаблонов",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    tc.userID,
+				ChannelId: "test-channel",
+				Message:   "/template " + strings.Join(tc.args, " "),
+			}
+
+			bot.handleTemplate(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestHandleClonePoll(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	members := model.ChannelMembers{{UserId: "alice"}, {UserId: "bob"}, {UserId: "carol"}}
+
+	tests := []struct {
+		name       string
+		poll       *tarantool.Poll
+		setupMocks func()
+	}{
+		{
+			name: "absolute quorum copied",
+			poll: &tarantool.Poll{Quorum: 2, Eligible: 10},
+			setupMocks: func() {
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(members, &model.Response{}, nil)
+				mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(created *tarantool.Poll) bool {
+					return created.PollID != "quorum-poll" &&
+						created.ChannelID == "test-channel" &&
+						created.Question == "Переносим релиз?" &&
+						created.Quorum == 2 &&
+						created.Eligible == 3 &&
+						created.Deadline == 0
+				})).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "test-channel" && strings.Contains(post.Message, "**Кворум**: 2 из 3 участников")
+				})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "percent quorum recomputed",
+			poll: &tarantool.Poll{Quorum: 5, QuorumPercent: 50, Eligible: 10},
+			setupMocks: func() {
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "test-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+				mockMM.On("GetChannelMembers", context.Background(), "test-channel", 0, channelMembersPage, "").Return(members, &model.Response{}, nil)
+				mockTarantool.On("CreatePoll", context.Background(), mock.MatchedBy(func(created *tarantool.Poll) bool {
+					return created.QuorumPercent == 50 &&
+						created.Quorum == 2 &&
+						created.Eligible == 3
+				})).Return(nil)
+				mockTarantool.On("SetPollPost", context.Background(), mock.AnythingOfType("string"), "poll-post").Return(nil)
+				mockMM.On("SaveReaction", context.Background(), mock.AnythingOfType("*model.Reaction")).Return(&model.Reaction{}, &model.Response{}, nil)
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return post.ChannelId == "test-channel" && strings.Contains(post.Message, "**Кворум**: 2 из 3 участников")
+				})).Return(&model.Post{Id: "poll-post"}, &model.Response{}, nil)
+			},
+		},
+		{
+			name: "not a member of source channel",
+			poll: &tarantool.Poll{Quorum: 2, Eligible: 10},
+			setupMocks: func() {
+				mockMM.On("GetChannelMember", context.Background(), "other-channel", "test-user", "").Return((*model.ChannelMember)(nil), &model.Response{StatusCode: 404}, errors.New("not found"))
+				mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+					return strings.Contains(post.Message, "Клонировать можно только голосование из канала")
+				})).Return(&model.Post{}, &model.Response{}, nil)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			poll := *tc.poll
+			poll.PollID = "quorum-poll"
+			poll.CreatorID = "creator-user"
+			poll.Question = "Переносим релиз?"
+			poll.Options = []string{"Да", "Нет"}
+			poll.Status = "active"
+			poll.ChannelID = "other-channel"
+			poll.Type = tarantool.PollSingle
+
+			mockTarantool.On("GetPoll", context.Background(), "quorum-poll").Return(&poll, nil)
+			tc.setupMocks()
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "test-user",
+				ChannelId: "test-channel",
+				Message:   "/clonepoll quorum-poll",
+			}
+
+			bot.handleClonePoll(post, []string{"quorum-poll"})
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
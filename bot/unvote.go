@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+func (b *Bot) handleUnvote(post *model.Post, args []string) {
+	if len(args) != 1 {
+		b.sendReply(post.ChannelId, "Использование: /unvote ID_ГОЛОСОВАНИЯ")
+		return
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), args[0])
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return
+	}
+
+	err = b.TarantoolClient.DeleteVote(b.ctx(), poll.PollID, post.UserId)
+	if errors.Is(err, tarantool.ErrNotFound) {
+		b.sendReply(post.ChannelId, "Вы ещё не голосовали")
+		return
+	}
+	if reply, ok := voteErrorReply(err); ok {
+		b.sendReply(post.ChannelId, reply)
+		return
+	}
+	if err != nil {
+		b.postLog(post).Error("Ошибка отзыва голоса", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось отозвать голос")
+		return
+	}
+
+	b.sendReply(post.ChannelId, "Ваш голос отозван")
+}
+
+// parseVoteChange задаёт политику изменения голоса: allowed, locked или
+// длительность до срока, после которой голос не меняется.
+func parseVoteChange(poll *tarantool.Poll, value string) error {
+	switch value {
+	case "allowed":
+		poll.VoteChange = tarantool.VoteChangeAllowed
+		return nil
+	case "locked":
+		poll.VoteChange = tarantool.VoteChangeLocked
+		return nil
+	}
+
+	cutoff, err := time.ParseDuration(value)
+	if err != nil || cutoff <= 0 || poll.Deadline == 0 {
+		return tarantool.ErrInvalidOption
+	}
+	poll.VoteChange = tarantool.VoteChangeUntil
+	poll.ChangeCutoff = int64(cutoff.Seconds())
+	return nil
+}
+
+// voteErrorReply переводит отказ в изменении голоса в ответ участнику.
+func voteErrorReply(err error) (string, bool) {
+	switch {
+	case errors.Is(err, tarantool.ErrPollClosed):
+		return "Голосование уже завершено", true
+	case errors.Is(err, tarantool.ErrVoteLocked):
+		return "В этом голосовании голос нельзя изменить или отозвать", true
+	case errors.Is(err, tarantool.ErrChangeClosed):
+		return "Срок изменения голоса истёк", true
+	case errors.Is(err, tarantool.ErrInvalidOption):
+		return "Неверный номер варианта", true
+	}
+	return "", false
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/mock"
+	"voting-bot/tarantool"
+)
+
+func TestHandleUnvote(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID: "test-poll",
+		Status: "active",
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name: "withdraw vote",
+			args: []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "test-poll", "voter-user").Return(nil)
+			},
+			reply: "Ваш голос отозван",
+		},
+		{
+			name: "not voted",
+			args: []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "test-poll", "voter-user").Return(tarantool.ErrNotFound)
+			},
+			reply: "Вы ещё не голосовали",
+		},
+		{
+			name: "change window closed",
+			args: []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "test-poll", "voter-user").Return(tarantool.ErrChangeClosed)
+			},
+			reply: "Срок изменения голоса истёк",
+		},
+		{
+			name: "closed poll",
+			args: []string{"test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockTarantool.On("DeleteVote", context.Background(), "test-poll", "voter-user").Return(tarantool.ErrPollClosed)
+			},
+			reply: "Голосование уже завершено",
+		},
+		{
+			name:       "missing poll id",
+			args:       nil,
+			setupMocks: func() {},
+			reply:      "Использование: /unvote",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    "voter-user",
+				ChannelId: "test-channel",
+				Message:   "/unvote " + strings.Join(tc.args, " "),
+			}
+
+			bot.handleUnvote(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
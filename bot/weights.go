@@ -0,0 +1,239 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/tarantool"
+)
+
+const weightUsage = "Использование:\n" +
+	"/weight set ID_ГОЛОСОВАНИЯ|channel @пользователь|group:ГРУППА ВЕС\n" +
+	"/weight remove ID_ГОЛОСОВАНИЯ|channel @пользователь|group:ГРУППА\n" +
+	"/weight list ID_ГОЛОСОВАНИЯ|channel"
+
+func (b *Bot) handleWeight(post *model.Post, args []string) {
+	if len(args) < 2 {
+		b.sendReply(post.ChannelId, weightUsage)
+		return
+	}
+
+	switch action := args[0]; {
+	case action == "list" && len(args) == 2:
+		b.listWeights(post, args[1])
+	case action == "set" && len(args) == 4:
+		b.setWeight(post, args[1], args[2], args[3])
+	case action == "remove" && len(args) == 3:
+		b.removeWeight(post, args[1], args[2])
+	default:
+		b.sendReply(post.ChannelId, weightUsage)
+	}
+}
+
+func (b *Bot) setWeight(post *model.Post, scope, subject, value string) {
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight <= 0 || math.IsInf(weight, 0) {
+		b.sendReply(post.ChannelId, "Вес должен быть положительным числом")
+		return
+	}
+
+	scopeID, ok := b.weightScope(post, scope)
+	if !ok {
+		return
+	}
+
+	key, name, err := b.resolveSubject(subject)
+	if err != nil {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Не найден участник или группа %s", subject))
+		return
+	}
+
+	err = b.TarantoolClient.SetWeight(b.ctx(), tarantool.Weight{
+		Scope:   scopeID,
+		Subject: key,
+		Value:   weight,
+		Name:    name,
+	})
+	if err != nil {
+		b.postLog(post).Error("Ошибка сохранения веса", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось сохранить вес")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Вес %s: %s", name, formatWeight(weight)))
+}
+
+func (b *Bot) removeWeight(post *model.Post, scope, subject string) {
+	scopeID, ok := b.weightScope(post, scope)
+	if !ok {
+		return
+	}
+
+	key, name, err := b.resolveSubject(subject)
+	if err != nil {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Не найден участник или группа %s", subject))
+		return
+	}
+
+	err = b.TarantoolClient.DeleteWeight(b.ctx(), scopeID, key)
+	if errors.Is(err, tarantool.ErrNotFound) {
+		b.sendReply(post.ChannelId, fmt.Sprintf("Для %s вес не задан", name))
+		return
+	}
+	if err != nil {
+		b.postLog(post).Error("Ошибка удаления веса", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось удалить вес")
+		return
+	}
+
+	b.sendReply(post.ChannelId, fmt.Sprintf("Вес %s сброшен", name))
+}
+
+func (b *Bot) listWeights(post *model.Post, scope string) {
+	scopeID := post.ChannelId
+	if scope != "channel" {
+		scopeID = scope
+	}
+
+	weights, err := b.TarantoolClient.GetWeights(b.ctx(), scopeID)
+	if err != nil {
+		b.postLog(post).Error("Ошибка получения весов", "err", err)
+		b.sendReply(post.ChannelId, "Не удалось получить веса")
+		return
+	}
+
+	if len(weights) == 0 {
+		b.sendReply(post.ChannelId, "Веса не настроены, все голоса равны")
+		return
+	}
+
+	response := "**Веса голосов**:\n"
+	for _, w := range weights {
+		response += fmt.Sprintf("- %s: %s\n", w.Name, formatWeight(w.Value))
+	}
+	b.sendReply(post.ChannelId, response)
+}
+
+// weightScope проверяет права на изменение весов: голосования — у его создателя,
+// канала — у администратора канала.
+func (b *Bot) weightScope(post *model.Post, scope string) (string, bool) {
+	if scope == "channel" {
+		member, _, err := b.Client.GetChannelMember(b.ctx(), post.ChannelId, post.UserId, "")
+		if err != nil || !member.SchemeAdmin {
+			b.sendReply(post.ChannelId, "Только администратор канала может настраивать веса канала")
+			return "", false
+		}
+		return post.ChannelId, true
+	}
+
+	poll, err := b.TarantoolClient.GetPoll(b.ctx(), scope)
+	if err != nil || poll == nil {
+		b.sendReply(post.ChannelId, "Голосование не найдено")
+		return "", false
+	}
+
+	if poll.CreatorID != post.UserId {
+		b.sendReply(post.ChannelId, "Только создатель может настраивать веса голосования")
+		return "", false
+	}
+	return poll.PollID, true
+}
+
+// resolveSubject превращает @username или group:NAME в ключ записи веса.
+func (b *Bot) resolveSubject(subject string) (key, name string, err error) {
+	if groupName, ok := strings.CutPrefix(subject, "group:"); ok {
+		groups, _, err := b.Client.GetGroups(b.ctx(), model.GroupSearchOpts{Q: groupName})
+		if err != nil {
+			return "", "", err
+		}
+		for _, group := range groups {
+			if group.Name != nil && *group.Name == groupName {
+				return "group:" + group.Id, subject, nil
+			}
+		}
+		return "", "", tarantool.ErrNotFound
+	}
+
+	user, _, err := b.Client.GetUserByUsername(b.ctx(), strings.TrimPrefix(subject, "@"), "")
+	if err != nil {
+		return "", "", err
+	}
+	return "user:" + user.Id, "@" + user.Username, nil
+}
+
+// pollResults возвращает результаты голосования, при наличии весов — со взвешенными итогами.
+func (b *Bot) pollResults(poll *tarantool.Poll) (*tarantool.VoteResult, error) {
+	results, err := b.TarantoolClient.GetResults(b.ctx(), poll.PollID)
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := b.voteWeights(poll)
+	if err != nil {
+		return nil, err
+	}
+	if weight != nil {
+		results.ApplyWeights(weight)
+	}
+	return results, nil
+}
+
+// voteWeights строит функцию веса участника. Веса голосования перекрывают веса
+// канала, личный вес — вес группы, из нескольких групп берётся наибольший.
+// Возвращает nil, если веса не настроены.
+func (b *Bot) voteWeights(poll *tarantool.Poll) (func(userID string) float64, error) {
+	table := make(map[string]float64)
+	for _, scope := range []string{poll.ChannelID, poll.PollID} {
+		if scope == "" {
+			continue
+		}
+
+		weights, err := b.TarantoolClient.GetWeights(b.ctx(), scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range weights {
+			table[w.Subject] = w.Value
+		}
+	}
+
+	if len(table) == 0 {
+		return nil, nil
+	}
+
+	users := make(map[string]float64)
+	groups := make(map[string]float64)
+	for subject, value := range table {
+		if userID, ok := strings.CutPrefix(subject, "user:"); ok {
+			users[userID] = value
+			continue
+		}
+
+		groupID := strings.TrimPrefix(subject, "group:")
+		members, _, err := b.Client.GetGroupMembers(b.ctx(), groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members.Members {
+			groups[member.Id] = math.Max(groups[member.Id], value)
+		}
+	}
+
+	return func(userID string) float64 {
+		if w, ok := users[userID]; ok {
+			return w
+		}
+		if w, ok := groups[userID]; ok {
+			return w
+		}
+		return 1
+	}, nil
+}
+
+func formatWeight(w float64) string {
+	return strconv.FormatFloat(math.Round(w*100)/100, 'f', -1, 64)
+}
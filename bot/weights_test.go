@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"voting-bot/tarantool"
+)
+
+func TestHandleWeight(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		CreatorID: "creator-user",
+		ChannelID: "test-channel",
+	}
+	groupName := "maintainers"
+
+	tests := []struct {
+		name       string
+		userID     string
+		args       []string
+		setupMocks func()
+		reply      string
+	}{
+		{
+			name:   "creator sets user weight",
+			userID: "creator-user",
+			args:   []string{"set", "test-poll", "@alice", "2"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+				mockMM.On("GetUserByUsername", context.Background(), "alice", "").Return(&model.User{Id: "alice-id", Username: "alice"}, &model.Response{}, nil)
+				mockTarantool.On("SetWeight", context.Background(), tarantool.Weight{
+					Scope:   "test-poll",
+					Subject: "user:alice-id",
+					Value:   2,
+					Name:    "@alice",
+				}).Return(nil)
+			},
+			reply: "Вес @alice: 2",
+		},
+		{
+			name:   "channel admin sets group weight",
+			userID: "admin-user",
+			args:   []string{"set", "channel", "group:maintainers", "1.5"},
+			setupMocks: func() {
+				mockMM.On("GetChannelMember", context.Background(), "test-channel", "admin-user", "").Return(&model.ChannelMember{SchemeAdmin: true}, &model.Response{}, nil)
+				mockMM.On("GetGroups", context.Background(), model.GroupSearchOpts{Q: "maintainers"}).Return([]*model.Group{{Id: "group-id", Name: &groupName}}, &model.Response{}, nil)
+				mockTarantool.On("SetWeight", context.Background(), tarantool.Weight{
+					Scope:   "test-channel",
+					Subject: "group:group-id",
+					Value:   1.5,
+					Name:    "group:maintainers",
+				}).Return(nil)
+			},
+			reply: "Вес group:maintainers: 1.5",
+		},
+		{
+			name:   "non-admin fails",
+			userID: "other-user",
+			args:   []string{"set", "channel", "@alice", "2"},
+			setupMocks: func() {
+				mockMM.On("GetChannelMember", context.Background(), "test-channel", "other-user", "").Return(&model.ChannelMember{}, &model.Response{}, nil)
+			},
+			reply: "Только администратор канала может настраивать веса канала",
+		},
+		{
+			name:   "non-creator fails",
+			userID: "other-user",
+			args:   []string{"remove", "test-poll", "@alice"},
+			setupMocks: func() {
+				mockTarantool.On("GetPoll", context.Background(), "test-poll").Return(poll, nil)
+			},
+			reply: "Только создатель может настраивать веса голосования",
+		},
+		{
+			name:       "invalid weight",
+			userID:     "creator-user",
+			args:       []string{"set", "test-poll", "@alice", "-1"},
+			setupMocks: func() {},
+			reply:      "Вес должен быть положительным числом",
+		},
+		{
+			name:   "list",
+			userID: "other-user",
+			args:   []string{"list", "test-poll"},
+			setupMocks: func() {
+				mockTarantool.On("GetWeights", context.Background(), "test-poll").Return([]tarantool.Weight{
+					{Scope: "test-poll", Subject: "user:alice-id", Value: 2, Name: "@alice"},
+				}, nil)
+			},
+			reply: "- @alice: 2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+				return strings.Contains(post.Message, tc.reply)
+			})).Return(&model.Post{}, &model.Response{}, nil)
+
+			bot := &Bot{
+				Client:          mockMM,
+				TarantoolClient: mockTarantool,
+			}
+
+			post := &model.Post{
+				UserId:    tc.userID,
+				ChannelId: "test-channel",
+				Message:   "/weight " + strings.Join(tc.args, " "),
+			}
+
+			bot.handleWeight(post, tc.args)
+
+			mockTarantool.AssertExpectations(t)
+			mockMM.AssertExpectations(t)
+
+			mockTarantool.ExpectedCalls = nil
+			mockMM.ExpectedCalls = nil
+		})
+	}
+}
+
+func TestVoteWeights(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	poll := &tarantool.Poll{
+		PollID:    "test-poll",
+		ChannelID: "test-channel",
+	}
+
+	mockTarantool.On("GetWeights", context.Background(), "test-channel").Return([]tarantool.Weight{
+		{Subject: "group:maintainers", Value: 2},
+		{Subject: "user:bob", Value: 3},
+	}, nil)
+	mockTarantool.On("GetWeights", context.Background(), "test-poll").Return([]tarantool.Weight{
+		{Subject: "user:bob", Value: 0.5},
+	}, nil)
+	mockMM.On("GetGroupMembers", context.Background(), "maintainers").Return(&model.GroupMemberList{
+		Members: []*model.User{{Id: "alice"}, {Id: "bob"}},
+	}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+	}
+
+	weight, err := bot.voteWeights(poll)
+	require.NoError(t, err)
+	require.NotNil(t, weight)
+
+	assert.Equal(t, 2.0, weight("alice"))
+	assert.Equal(t, 0.5, weight("bob"))
+	assert.Equal(t, 1.0, weight("carol"))
+}
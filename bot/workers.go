@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"voting-bot/metrics"
+)
+
+const (
+	DefaultWorkers   = 8
+	DefaultQueueSize = 64
+)
+
+// workerPool выполняет обработчики событий параллельно. Задачи с одним
+// ключом попадают в одну очередь и выполняются по порядку.
+type workerPool struct {
+	queues  []chan func()
+	wg      sync.WaitGroup
+	logger  *slog.Logger
+	metrics *metrics.Metrics
+}
+
+func newWorkerPool(workers, queueSize int, logger *slog.Logger, m *metrics.Metrics) *workerPool {
+	pool := &workerPool{queues: make([]chan func(), max(workers, 1)), logger: logger, metrics: m}
+	for i := range pool.queues {
+		queue := make(chan func(), max(queueSize, 0))
+		pool.queues[i] = queue
+
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			for job := range queue {
+				pool.metrics.QueueChanged(-1)
+				job()
+			}
+		}()
+	}
+	return pool
+}
+
+// submit ставит задачу в очередь ключа. Если очередь заполнена, submit ждёт
+// освобождения места, так что чтение новых событий приостанавливается.
+func (p *workerPool) submit(ctx context.Context, key string, job func()) error {
+	queue := p.queues[p.index(key)]
+	// Учитывается до отправки: обработчик может забрать задачу сразу
+	p.metrics.QueueChanged(1)
+
+	select {
+	case queue <- job:
+		return nil
+	default:
+	}
+
+	p.logger.Warn("Очередь обработки заполнена, приём событий приостановлен")
+	select {
+	case queue <- job:
+		return nil
+	case <-ctx.Done():
+		p.metrics.QueueChanged(-1)
+		return ctx.Err()
+	}
+}
+
+func (p *workerPool) index(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// stop выполняет уже поставленные задачи и дожидается остановки обработчиков.
+func (p *workerPool) stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+// dispatch передаёт событие в пул обработчиков. События одного пользователя
+// выполняются по порядку, поэтому его голоса за одно голосование не
+// переставляются, а медленная команда не задерживает остальных.
+func (b *Bot) dispatch(ctx context.Context, event *model.WebSocketEvent) error {
+	switch event.EventType() {
+	case model.WebsocketEventPosted:
+		post, ok := b.parsePostEvent(event)
+		if !ok {
+			return nil
+		}
+		return b.pool.submit(ctx, post.UserId, func() {
+			defer b.logPanic("событие "+string(event.EventType()), event.GetData())
+			b.handlePost(post)
+		})
+	case model.WebsocketEventReactionAdded, model.WebsocketEventReactionRemoved:
+		reaction, ok := b.parseReactionEvent(event)
+		if !ok {
+			return nil
+		}
+		added := event.EventType() == model.WebsocketEventReactionAdded
+		return b.pool.submit(ctx, reaction.UserId, func() {
+			defer b.logPanic("событие "+string(event.EventType()), event.GetData())
+			b.handleReaction(reaction, added)
+		})
+	}
+	return nil
+}
+
+// logPanic вызывается через defer и не даёт панике в обработчике остановить
+// бота: записывает в лог, что обрабатывалось, и стек, после чего работа
+// продолжается со следующего события.
+func (b *Bot) logPanic(what string, details any) {
+	if r := recover(); r != nil {
+		b.logger().Error("Паника при обработке", "what", what, "panic", r, "details", details, "stack", string(debug.Stack()))
+	}
+}
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// keysOnDifferentWorkers подбирает два ключа, которые попадают в разные очереди.
+func keysOnDifferentWorkers(pool *workerPool) (string, string) {
+	for i := 1; ; i++ {
+		key := fmt.Sprintf("user%d", i)
+		if pool.index(key) != pool.index("user0") {
+			return "user0", key
+		}
+	}
+}
+
+func TestWorkerPoolOrdering(t *testing.T) {
+	pool := newWorkerPool(4, 2, slog.Default(), nil)
+
+	var mu sync.Mutex
+	handled := make(map[string][]int)
+	for i := range 50 {
+		for _, key := range []string{"user1", "user2", "user3", "user4", "user5"} {
+			require.NoError(t, pool.submit(context.Background(), key, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				handled[key] = append(handled[key], i)
+			}))
+		}
+	}
+	pool.stop()
+
+	for key, order := range handled {
+		assert.Len(t, order, 50, key)
+		assert.IsIncreasing(t, order, key)
+	}
+	assert.Len(t, handled, 5)
+}
+
+func TestWorkerPoolConcurrency(t *testing.T) {
+	pool := newWorkerPool(2, 1, slog.Default(), nil)
+	slowKey, fastKey := keysOnDifferentWorkers(pool)
+
+	release := make(chan struct{})
+	fastDone := make(chan struct{})
+	require.NoError(t, pool.submit(context.Background(), slowKey, func() { <-release }))
+	require.NoError(t, pool.submit(context.Background(), fastKey, func() { close(fastDone) }))
+
+	// Медленная команда одного пользователя не задерживает другого
+	select {
+	case <-fastDone:
+	case <-time.After(time.Second):
+		t.Fatal("fast job blocked by slow one")
+	}
+
+	close(release)
+	pool.stop()
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	pool := newWorkerPool(1, 1, slog.Default(), nil)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.NoError(t, pool.submit(context.Background(), "user", func() {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.NoError(t, pool.submit(context.Background(), "user", func() {}))
+
+	// Очередь заполнена: приём ждёт, пока не отменят контекст
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := pool.submit(ctx, "user", func() {})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	pool.stop()
+}
+
+func TestDispatch(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	mockTarantool.On("ClaimPost", context.Background(), "post", "test-channel", int64(0)).Return(true, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "Использование: /results ID_ГОЛОСОВАНИЯ"
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+		pool:            newWorkerPool(2, 1, slog.Default(), nil),
+	}
+
+	event := postedEvent(t, &model.Post{Id: "post", ChannelId: "test-channel", UserId: "user", Message: "/results"})
+	require.NoError(t, bot.dispatch(context.Background(), event))
+	// Остальные события не обрабатываются
+	require.NoError(t, bot.dispatch(context.Background(), model.NewWebSocketEvent(model.WebsocketEventTyping, "", "", "", nil, "")))
+	bot.pool.stop()
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
+
+func TestDispatchRecoversPanic(t *testing.T) {
+	mockTarantool := new(MockTarantool)
+	mockMM := new(MockMattermostClient)
+
+	// Первая команда падает, следующая того же пользователя выполняется
+	mockTarantool.On("ClaimPost", context.Background(), "broken", "test-channel", int64(0)).Run(func(args mock.Arguments) {
+		panic("malformed tuple")
+	}).Return(false, nil)
+	mockTarantool.On("ClaimPost", context.Background(), "post", "test-channel", int64(0)).Return(true, nil)
+	mockMM.On("CreatePost", context.Background(), mock.MatchedBy(func(post *model.Post) bool {
+		return post.Message == "Использование: /results ID_ГОЛОСОВАНИЯ"
+	})).Return(&model.Post{}, &model.Response{}, nil)
+
+	bot := &Bot{
+		Client:          mockMM,
+		TarantoolClient: mockTarantool,
+		UserID:          "bot-user",
+		pool:            newWorkerPool(1, 2, slog.Default(), nil),
+	}
+
+	events := []*model.WebSocketEvent{
+		postedEvent(t, &model.Post{Id: "broken", ChannelId: "test-channel", UserId: "user", Message: "/results"}),
+		// Событие без поста и пустая реакция пропускаются
+		model.NewWebSocketEvent(model.WebsocketEventPosted, "", "", "", nil, ""),
+		model.NewWebSocketEvent(model.WebsocketEventReactionAdded, "", "", "", nil, "").SetData(map[string]any{"reaction": "null"}),
+		postedEvent(t, &model.Post{Id: "post", ChannelId: "test-channel", UserId: "user", Message: "/results"}),
+	}
+	for _, event := range events {
+		require.NoError(t, bot.dispatch(context.Background(), event))
+	}
+	bot.pool.stop()
+
+	mockTarantool.AssertExpectations(t)
+	mockMM.AssertExpectations(t)
+}
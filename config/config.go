@@ -0,0 +1,308 @@
+// Package config собирает настройки бота из файла, переменных окружения и
+// флагов командной строки. Источники применяются по порядку: значения по
+// умолчанию, YAML-файл, окружение, флаги — каждый следующий перекрывает
+// предыдущий.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted заменяет значения секретов в выводе --print-config.
+const redacted = "[скрыто]"
+
+type Config struct {
+	Mattermost Mattermost `yaml:"mattermost"`
+	Tarantool  Tarantool  `yaml:"tarantool"`
+	Bot        Bot        `yaml:"bot"`
+	Log        Log        `yaml:"log"`
+	// HealthAddr — адрес HTTP-сервера с /healthz, /readyz и /metrics, пустой — сервер выключен.
+	HealthAddr string `yaml:"health_addr"`
+	// AdminAddr — адрес HTTP-сервера с /loglevel, пустой — сервер выключен.
+	// Запросы к нему не проверяются, поэтому его не стоит открывать наружу.
+	AdminAddr string `yaml:"admin_addr"`
+}
+
+type Mattermost struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+type Tarantool struct {
+	Address           string        `yaml:"address"`
+	User              string        `yaml:"user"`
+	Password          string        `yaml:"password"`
+	Timeout           time.Duration `yaml:"timeout"`
+	ReconnectInterval time.Duration `yaml:"reconnect_interval"`
+	MaxReconnects     int           `yaml:"max_reconnects"` // 0 — без ограничения
+}
+
+type Bot struct {
+	Workers              int           `yaml:"workers"`
+	QueueSize            int           `yaml:"queue_size"`
+	ShutdownTimeout      time.Duration `yaml:"shutdown_timeout"`
+	MaxReconnectAttempts int           `yaml:"max_reconnect_attempts"` // 0 — без ограничения
+}
+
+type Log struct {
+	Level  string `yaml:"level"`  // debug, info, warn или error
+	Format string `yaml:"format"` // text или json
+}
+
+// Форматы журнала.
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// ParseLevel разбирает уровень журнала: debug, info, warn или error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("%q — не уровень журнала, ожидается debug, info, warn или error", s)
+	}
+	return level, nil
+}
+
+// setting связывает поле настроек с ключом файла, переменной окружения и флагом.
+type setting struct {
+	key    string // ключ в файле: tarantool.address
+	env    string
+	flag   string
+	usage  string
+	secret bool // значение можно передать файлом через <env>_FILE и оно скрывается при выводе
+	value  flag.Value
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "mattermost.url", env: "MATTERMOST_URL", flag: "mattermost-url", usage: "адрес сервера Mattermost", value: (*stringValue)(&c.Mattermost.URL)},
+		{key: "mattermost.token", env: "MATTERMOST_TOKEN", flag: "mattermost-token", usage: "токен бота", secret: true, value: (*stringValue)(&c.Mattermost.Token)},
+		{key: "tarantool.address", env: "TARANTOOL_ADDRESS", flag: "tarantool-address", usage: "адрес Tarantool, host:port", value: (*stringValue)(&c.Tarantool.Address)},
+		{key: "tarantool.user", env: "TARANTOOL_USER", flag: "tarantool-user", usage: "пользователь Tarantool", value: (*stringValue)(&c.Tarantool.User)},
+		{key: "tarantool.password", env: "TARANTOOL_PASSWORD", flag: "tarantool-password", usage: "пароль Tarantool", secret: true, value: (*stringValue)(&c.Tarantool.Password)},
+		{key: "tarantool.timeout", env: "TARANTOOL_TIMEOUT", flag: "tarantool-timeout", usage: "таймаут запроса к Tarantool", value: (*durationValue)(&c.Tarantool.Timeout)},
+		{key: "tarantool.reconnect_interval", env: "TARANTOOL_RECONNECT_INTERVAL", flag: "tarantool-reconnect-interval", usage: "пауза между переподключениями к Tarantool", value: (*durationValue)(&c.Tarantool.ReconnectInterval)},
+		{key: "tarantool.max_reconnects", env: "TARANTOOL_MAX_RECONNECTS", flag: "tarantool-max-reconnects", usage: "попыток переподключения к Tarantool, 0 — без ограничения", value: (*intValue)(&c.Tarantool.MaxReconnects)},
+		{key: "bot.workers", env: "BOT_WORKERS", flag: "workers", usage: "параллельных обработчиков команд", value: (*intValue)(&c.Bot.Workers)},
+		{key: "bot.queue_size", env: "BOT_QUEUE_SIZE", flag: "queue-size", usage: "длина очереди каждого обработчика", value: (*intValue)(&c.Bot.QueueSize)},
+		{key: "bot.shutdown_timeout", env: "BOT_SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "ожидание начатых команд при остановке", value: (*durationValue)(&c.Bot.ShutdownTimeout)},
+		{key: "bot.max_reconnect_attempts", env: "BOT_MAX_RECONNECT_ATTEMPTS", flag: "max-reconnect-attempts", usage: "попыток переподключения к Mattermost, 0 — без ограничения", value: (*intValue)(&c.Bot.MaxReconnectAttempts)},
+		{key: "log.level", env: "LOG_LEVEL", flag: "log-level", usage: "уровень журнала: debug, info, warn, error", value: (*stringValue)(&c.Log.Level)},
+		{key: "log.format", env: "LOG_FORMAT", flag: "log-format", usage: "формат журнала: text или json", value: (*stringValue)(&c.Log.Format)},
+		{key: "health_addr", env: "HEALTH_ADDR", flag: "health-addr", usage: "адрес HTTP-сервера с /healthz, /readyz и /metrics", value: (*stringValue)(&c.HealthAddr)},
+		{key: "admin_addr", env: "ADMIN_ADDR", flag: "admin-addr", usage: "адрес HTTP-сервера с /loglevel, только для внутренней сети", value: (*stringValue)(&c.AdminAddr)},
+	}
+}
+
+// Load собирает настройки из args (без имени программы) и окружения поверх
+// defaults. Значения по умолчанию передаёт вызывающий: они принадлежат
+// пакетам, которые эти настройки используют.
+// printConfig сообщает, что запрошен вывод настроек через --print-config.
+func Load(defaults Config, args []string, getenv func(string) string) (cfg *Config, printConfig bool, err error) {
+	cfg = &defaults
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("voting-bot", flag.ContinueOnError)
+	configPath := fs.String("config", getenv("BOT_CONFIG"), "путь к YAML-файлу настроек (BOT_CONFIG)")
+	fs.BoolVar(&printConfig, "print-config", false, "вывести итоговые настройки со скрытыми секретами и выйти")
+
+	// Флаги применяются последними, поэтому сначала только запоминаются
+	flags := make(map[string]string)
+	for _, s := range settings {
+		fs.Func(s.flag, fmt.Sprintf("%s (%s)", s.usage, s.env), func(value string) error {
+			flags[s.flag] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("лишние аргументы: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if *configPath != "" {
+		if err := cfg.readFile(*configPath); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, s := range settings {
+		if err := s.applyEnv(getenv); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flags[s.flag]; ok {
+			if err := s.value.Set(value); err != nil {
+				return nil, false, fmt.Errorf("флаг --%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	return cfg, printConfig, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("чтение файла настроек: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("файл настроек %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s setting) applyEnv(getenv func(string) string) error {
+	if s.secret {
+		if path := getenv(s.env + "_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s_FILE: %w", s.env, err)
+			}
+			return s.value.Set(strings.TrimSpace(string(data)))
+		}
+	}
+
+	if value := getenv(s.env); value != "" {
+		if err := s.value.Set(value); err != nil {
+			return fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет настройки и перечисляет все ошибки сразу, с подсказкой,
+// где задать каждое значение.
+func (c *Config) Validate() error {
+	var errs []error
+	where := func(key string) string {
+		for _, s := range c.settings() {
+			if s.key == key && s.secret {
+				return fmt.Sprintf("%s в файле, %s, %s_FILE или --%s", s.key, s.env, s.env, s.flag)
+			}
+			if s.key == key {
+				return fmt.Sprintf("%s в файле, %s или --%s", s.key, s.env, s.flag)
+			}
+		}
+		return key
+	}
+
+	if c.Mattermost.URL == "" {
+		errs = append(errs, fmt.Errorf("не задан адрес Mattermost: укажите %s", where("mattermost.url")))
+	} else if u, err := url.Parse(c.Mattermost.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("адрес Mattermost %q должен быть вида https://host[:port]: исправьте %s", c.Mattermost.URL, where("mattermost.url")))
+	}
+	if c.Mattermost.Token == "" {
+		errs = append(errs, fmt.Errorf("не задан токен бота: укажите %s", where("mattermost.token")))
+	}
+
+	if c.Tarantool.Address == "" {
+		errs = append(errs, fmt.Errorf("не задан адрес Tarantool: укажите %s", where("tarantool.address")))
+	} else if _, _, err := net.SplitHostPort(c.Tarantool.Address); err != nil {
+		errs = append(errs, fmt.Errorf("адрес Tarantool %q должен быть вида host:port: исправьте %s", c.Tarantool.Address, where("tarantool.address")))
+	}
+	if c.Tarantool.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("таймаут Tarantool должен быть больше нуля: исправьте %s", where("tarantool.timeout")))
+	}
+	if c.Tarantool.ReconnectInterval <= 0 {
+		errs = append(errs, fmt.Errorf("пауза переподключения к Tarantool должна быть больше нуля: исправьте %s", where("tarantool.reconnect_interval")))
+	}
+	if c.Tarantool.MaxReconnects < 0 {
+		errs = append(errs, fmt.Errorf("число переподключений к Tarantool не может быть отрицательным: исправьте %s", where("tarantool.max_reconnects")))
+	}
+
+	if c.Bot.Workers < 1 {
+		errs = append(errs, fmt.Errorf("нужен хотя бы один обработчик: исправьте %s", where("bot.workers")))
+	}
+	if c.Bot.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("длина очереди не может быть отрицательной: исправьте %s", where("bot.queue_size")))
+	}
+	if c.Bot.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("ожидание при остановке должно быть больше нуля: исправьте %s", where("bot.shutdown_timeout")))
+	}
+	if c.Bot.MaxReconnectAttempts < 0 {
+		errs = append(errs, fmt.Errorf("число переподключений к Mattermost не может быть отрицательным: исправьте %s", where("bot.max_reconnect_attempts")))
+	}
+
+	if _, err := ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("%w: исправьте %s", err, where("log.level")))
+	}
+	if c.Log.Format != LogText && c.Log.Format != LogJSON {
+		errs = append(errs, fmt.Errorf("формат журнала %q должен быть text или json: исправьте %s", c.Log.Format, where("log.format")))
+	}
+
+	if c.AdminAddr != "" && c.AdminAddr == c.HealthAddr {
+		errs = append(errs, fmt.Errorf("/loglevel нельзя открывать на адресе проверок %q: исправьте %s", c.HealthAddr, where("admin_addr")))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print выводит настройки в формате файла, заменяя заданные секреты.
+func (c *Config) Print(w io.Writer) error {
+	printed := *c
+	if printed.Mattermost.Token != "" {
+		printed.Mattermost.Token = redacted
+	}
+	if printed.Tarantool.Password != "" {
+		printed.Tarantool.Password = redacted
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&printed); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q — не целое число", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q — не длительность, ожидается например 10s или 1m", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
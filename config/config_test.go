@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// testDefaults повторяет значения по умолчанию, которые передаёт main.
+func testDefaults() *Config {
+	return &Config{
+		Tarantool: Tarantool{Timeout: 10 * time.Second, ReconnectInterval: 5 * time.Second, MaxReconnects: 5},
+		Bot:       Bot{Workers: 8, QueueSize: 64, ShutdownTimeout: 30 * time.Second, MaxReconnectAttempts: 10},
+		Log:       Log{Level: "info", Format: LogText},
+	}
+}
+
+func envFrom(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestLoad(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+mattermost:
+  url: https://file.example.com
+  token: file-token
+tarantool:
+  address: file:3301
+  timeout: 3s
+bot:
+  workers: 2
+`)
+	tokenFile := writeFile(t, "token", "secret-token\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, testDefaults(), cfg)
+			},
+		},
+		{
+			name: "file",
+			args: []string{"--config", file},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "https://file.example.com", cfg.Mattermost.URL)
+				assert.Equal(t, "file:3301", cfg.Tarantool.Address)
+				assert.Equal(t, 3*time.Second, cfg.Tarantool.Timeout)
+				assert.Equal(t, 2, cfg.Bot.Workers)
+				// Не заданные в файле поля сохраняют значения по умолчанию
+				assert.Equal(t, testDefaults().Bot.ShutdownTimeout, cfg.Bot.ShutdownTimeout)
+			},
+		},
+		{
+			name: "env overrides file",
+			env:  map[string]string{"BOT_CONFIG": file, "TARANTOOL_ADDRESS": "env:3301", "BOT_WORKERS": "4"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "https://file.example.com", cfg.Mattermost.URL)
+				assert.Equal(t, "env:3301", cfg.Tarantool.Address)
+				assert.Equal(t, 4, cfg.Bot.Workers)
+			},
+		},
+		{
+			name: "flags override env",
+			args: []string{"--config", file, "--tarantool-address", "flag:3301", "--shutdown-timeout", "5s"},
+			env:  map[string]string{"TARANTOOL_ADDRESS": "env:3301"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "flag:3301", cfg.Tarantool.Address)
+				assert.Equal(t, 5*time.Second, cfg.Bot.ShutdownTimeout)
+			},
+		},
+		{
+			name: "secret from file",
+			env:  map[string]string{"MATTERMOST_TOKEN": "env-token", "MATTERMOST_TOKEN_FILE": tokenFile},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "secret-token", cfg.Mattermost.Token)
+			},
+		},
+		{
+			name: "log settings",
+			args: []string{"--log-level", "debug"},
+			env:  map[string]string{"LOG_LEVEL": "warn", "LOG_FORMAT": "json"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "debug", cfg.Log.Level)
+				assert.Equal(t, LogJSON, cfg.Log.Format)
+			},
+		},
+		{
+			name:    "missing secret file",
+			env:     map[string]string{"TARANTOOL_PASSWORD_FILE": "/nonexistent/password"},
+			wantErr: "TARANTOOL_PASSWORD_FILE",
+		},
+		{
+			name:    "invalid duration",
+			env:     map[string]string{"TARANTOOL_TIMEOUT": "10"},
+			wantErr: `TARANTOOL_TIMEOUT: "10" — не длительность`,
+		},
+		{
+			name:    "invalid number flag",
+			args:    []string{"--workers", "many"},
+			wantErr: `"many" — не целое число`,
+		},
+		{
+			name:    "unknown key in file",
+			args:    []string{"--config", writeFile(t, "typo.yaml", "tarantool:\n  adress: localhost:3301\n")},
+			wantErr: "field adress not found",
+		},
+		{
+			name:    "extra arguments",
+			args:    []string{"run"},
+			wantErr: "лишние аргументы: run",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, _, err := Load(*testDefaults(), tc.args, envFrom(tc.env))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, cfg)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		cfg := testDefaults()
+		cfg.Mattermost.URL = "https://mm.example.com"
+		cfg.Mattermost.Token = "token"
+		cfg.Tarantool.Address = "localhost:3301"
+		return cfg
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "empty",
+			modify: func(cfg *Config) {
+				*cfg = *testDefaults()
+			},
+			wantErr: []string{
+				"не задан адрес Mattermost: укажите mattermost.url в файле, MATTERMOST_URL или --mattermost-url",
+				"не задан токен бота: укажите mattermost.token в файле, MATTERMOST_TOKEN, MATTERMOST_TOKEN_FILE или --mattermost-token",
+				"не задан адрес Tarantool",
+			},
+		},
+		{
+			name: "malformed addresses",
+			modify: func(cfg *Config) {
+				cfg.Mattermost.URL = "mm.example.com"
+				cfg.Tarantool.Address = "localhost"
+			},
+			wantErr: []string{
+				`адрес Mattermost "mm.example.com" должен быть вида https://host[:port]`,
+				`адрес Tarantool "localhost" должен быть вида host:port`,
+			},
+		},
+		{
+			name: "out of range",
+			modify: func(cfg *Config) {
+				cfg.Bot.Workers = 0
+				cfg.Tarantool.Timeout = 0
+			},
+			wantErr: []string{"bot.workers", "tarantool.timeout"},
+		},
+		{
+			name: "admin on health address",
+			modify: func(cfg *Config) {
+				cfg.HealthAddr = ":8080"
+				cfg.AdminAddr = ":8080"
+			},
+			wantErr: []string{`/loglevel нельзя открывать на адресе проверок ":8080": исправьте admin_addr в файле, ADMIN_ADDR или --admin-addr`},
+		},
+		{
+			name: "bad log settings",
+			modify: func(cfg *Config) {
+				cfg.Log.Level = "verbose"
+				cfg.Log.Format = "xml"
+			},
+			wantErr: []string{
+				`"verbose" — не уровень журнала, ожидается debug, info, warn или error: исправьте log.level в файле, LOG_LEVEL или --log-level`,
+				`формат журнала "xml" должен быть text или json`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := valid()
+			tc.modify(cfg)
+
+			err := cfg.Validate()
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			for _, want := range tc.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	cfg := testDefaults()
+	cfg.Mattermost.URL = "https://mm.example.com"
+	cfg.Mattermost.Token = "token-value"
+	cfg.Tarantool.Password = "password-value"
+
+	var out strings.Builder
+	require.NoError(t, cfg.Print(&out))
+
+	assert.NotContains(t, out.String(), "token-value")
+	assert.NotContains(t, out.String(), "password-value")
+	assert.Contains(t, out.String(), "url: https://mm.example.com")
+	assert.Contains(t, out.String(), "timeout: 10s")
+	assert.Equal(t, "token-value", cfg.Mattermost.Token)
+
+	// Выведенные настройки читаются обратно как файл
+	printed, _, err := Load(*testDefaults(), []string{"--config", writeFile(t, "printed.yaml", out.String())}, envFrom(nil))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Mattermost.URL, printed.Mattermost.URL)
+	assert.Equal(t, cfg.Bot, printed.Bot)
+	assert.Equal(t, cfg.Tarantool.Timeout, printed.Tarantool.Timeout)
+}
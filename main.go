@@ -1,30 +1,207 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"voting-bot/bot"
+	"voting-bot/config"
+	"voting-bot/metrics"
 	"voting-bot/tarantool"
 )
 
+// defaultConfig возвращает настройки по умолчанию, поверх которых
+// применяются файл, окружение и флаги.
+func defaultConfig() config.Config {
+	return config.Config{
+		Tarantool: config.Tarantool{
+			Timeout:           tarantool.DefaultTimeout,
+			ReconnectInterval: tarantool.DefaultReconnect,
+			MaxReconnects:     tarantool.DefaultMaxReconnects,
+		},
+		Bot: config.Bot{
+			Workers:              bot.DefaultWorkers,
+			QueueSize:            bot.DefaultQueueSize,
+			ShutdownTimeout:      bot.DefaultShutdownTimeout,
+			MaxReconnectAttempts: bot.DefaultMaxReconnectAttempts,
+		},
+		Log: config.Log{
+			Level:  "info",
+			Format: config.LogText,
+		},
+	}
+}
+
 func main() {
-	tarantoolAddr := os.Getenv("TARANTOOL_ADDRESS")
-	tarantoolUser := os.Getenv("TARANTOOL_USER")
-	tarantoolPass := os.Getenv("TARANTOOL_PASSWORD")
+	cfg, printConfig, err := config.Load(defaultConfig(), os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	if printConfig {
+		return
+	}
+
+	// Уровень можно менять на ходу через /loglevel
+	initial, _ := config.ParseLevel(cfg.Log.Level) // уже проверен Validate
+	level := new(slog.LevelVar)
+	level.Set(initial)
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	if cfg.Log.Format == config.LogJSON {
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	botMetrics := metrics.New(prometheus.DefaultRegisterer)
+
+	tc, err := tarantool.Connect(cfg.Tarantool.Address, tarantool.Options{
+		User:          cfg.Tarantool.User,
+		Password:      cfg.Tarantool.Password,
+		Timeout:       cfg.Tarantool.Timeout,
+		Reconnect:     cfg.Tarantool.ReconnectInterval,
+		MaxReconnects: uint(cfg.Tarantool.MaxReconnects),
+		Logger:        logger.With("component", "tarantool"),
+		Metrics:       botMetrics,
+	})
+	if err != nil {
+		fatal("Failed to connect to Tarantool", err)
+	}
 
-	tc, err := tarantool.NewTarantoolClient(tarantoolAddr, tarantoolUser, tarantoolPass)
+	votingBot, err := bot.NewBot(cfg.Mattermost.URL, cfg.Mattermost.Token, tc)
 	if err != nil {
-		log.Fatalf("Failed to connect to Tarantool: %v", err)
+		fatal("Failed to create bot", err)
+	}
+	votingBot.Logger = logger.With("component", "bot")
+	votingBot.SetMetrics(botMetrics)
+	votingBot.Workers = cfg.Bot.Workers
+	votingBot.QueueSize = cfg.Bot.QueueSize
+	votingBot.ShutdownTimeout = cfg.Bot.ShutdownTimeout
+	votingBot.MaxReconnectAttempts = cfg.Bot.MaxReconnectAttempts
+
+	if cfg.HealthAddr != "" {
+		go serveHealth(cfg.HealthAddr, votingBot)
+	}
+	if cfg.AdminAddr != "" {
+		go serveAdmin(cfg.AdminAddr, level)
 	}
 
-	mattermostURL := os.Getenv("MATTERMOST_URL")
-	botToken := os.Getenv("MATTERMOST_TOKEN")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	votingBot, err := bot.NewBot(mattermostURL, botToken, tc)
+	err = votingBot.Listen(ctx)
+	if closeErr := tc.Close(); closeErr != nil {
+		slog.Error("Failed to close Tarantool connection", "err", closeErr)
+	}
 	if err != nil {
-		log.Fatalf("Failed to create bot: %v", err)
+		fatal("Lost connection to Mattermost", err)
+	}
+	slog.Info("Bot stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// readyTimeout ограничивает проверки готовности одного запроса /readyz.
+const readyTimeout = 5 * time.Second
+
+// serveHealth — HTTP-сервер для оркестратора и мониторинга:
+//   - /healthz — 200, пока цикл обработки событий работает (liveness);
+//   - /readyz — 200, если Tarantool отвечает, схема актуальна и соединение
+//     с Mattermost открыто, иначе 503 со списком проверок (readiness);
+//   - /metrics — метрики Prometheus.
+func serveHealth(addr string, votingBot *bot.Bot) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := votingBot.Alive(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+
+		checks, ready := votingBot.Ready(ctx)
+		w.Header().Set("Content-Type", "application/json")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"ready":      ready,
+			"checks":     checks,
+			"connection": votingBot.ConnectionState(),
+		})
+	})
+	mux.Handle("/metrics", promhttp.Handler())
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("Health endpoint stopped", "err", err)
 	}
+}
 
-	votingBot.Listen()
-	select {}
+// serveAdmin — HTTP-сервер для управления ботом на ходу, отдельный от
+// serveHealth: адрес проверок обычно доступен оркестратору и мониторингу,
+// а запросы сюда не проверяются, поэтому он должен слушать только
+// localhost или внутреннюю сеть.
+//   - /loglevel — GET возвращает уровень журнала, PUT с телом вида "debug"
+//     меняет его.
+func serveAdmin(addr string, level *slog.LevelVar) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/loglevel", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			parsed, err := config.ParseLevel(strings.TrimSpace(string(body)))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level.Set(parsed)
+			slog.Info("Log level changed", "level", parsed)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprintln(w, level.Level())
+	})
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		slog.Error("Admin endpoint stopped", "err", err)
+	}
 }
@@ -0,0 +1,140 @@
+// Package metrics описывает метрики Prometheus бота. Методы Metrics
+// безопасно вызывать у nil: без метрик бот и клиент Tarantool работают как
+// прежде.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "voting_bot"
+
+// Итоги обработки команды.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+	OutcomePanic = "panic"
+)
+
+type Metrics struct {
+	commands          *prometheus.CounterVec
+	votes             prometheus.Counter
+	pollsCreated      prometheus.Counter
+	pollsClosed       prometheus.Counter
+	tarantoolDuration *prometheus.HistogramVec
+	mattermostErrors  *prometheus.CounterVec
+	reconnects        prometheus.Counter
+	queueDepth        prometheus.Gauge
+}
+
+// New создаёт метрики и регистрирует их в reg.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "commands_total",
+			Help:      "Обработанные команды по имени и итогу (ok, error, panic).",
+		}, []string{"command", "outcome"}),
+		votes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "votes_total",
+			Help:      "Принятые голоса, включая голоса реакциями.",
+		}),
+		pollsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "polls_created_total",
+			Help:      "Созданные голосования.",
+		}),
+		pollsClosed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "polls_closed_total",
+			Help:      "Завершённые голосования, вручную и по сроку.",
+		}),
+		tarantoolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tarantool_request_duration_seconds",
+			Help:      "Время запросов к Tarantool по операциям.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"operation"}),
+		mattermostErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mattermost_errors_total",
+			Help:      "Ошибки запросов к API Mattermost по операциям.",
+		}, []string{"operation"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "websocket_reconnects_total",
+			Help:      "Переподключения к Mattermost после потери соединения.",
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "События, ожидающие обработки в очередях.",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.commands, m.votes, m.pollsCreated, m.pollsClosed,
+			m.tarantoolDuration, m.mattermostErrors, m.reconnects, m.queueDepth)
+	}
+	return m
+}
+
+// CommandHandled учитывает обработанную команду.
+func (m *Metrics) CommandHandled(command, outcome string) {
+	if m != nil {
+		m.commands.WithLabelValues(command, outcome).Inc()
+	}
+}
+
+// VoteCast учитывает принятый голос.
+func (m *Metrics) VoteCast() {
+	if m != nil {
+		m.votes.Inc()
+	}
+}
+
+// PollCreated учитывает созданное голосование.
+func (m *Metrics) PollCreated() {
+	if m != nil {
+		m.pollsCreated.Inc()
+	}
+}
+
+// PollClosed учитывает завершённое голосование.
+func (m *Metrics) PollClosed() {
+	if m != nil {
+		m.pollsClosed.Inc()
+	}
+}
+
+// ObserveTarantool записывает время операции с Tarantool, начатой в start.
+// Вызывается через defer.
+func (m *Metrics) ObserveTarantool(operation string, start time.Time) {
+	if m != nil {
+		m.tarantoolDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MattermostError учитывает ошибку запроса к API Mattermost.
+func (m *Metrics) MattermostError(operation string) {
+	if m != nil {
+		m.mattermostErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// Reconnected учитывает переподключение к Mattermost.
+func (m *Metrics) Reconnected() {
+	if m != nil {
+		m.reconnects.Inc()
+	}
+}
+
+// QueueChanged меняет число событий в очередях на delta.
+func (m *Metrics) QueueChanged(delta int) {
+	if m != nil {
+		m.queueDepth.Add(float64(delta))
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.CommandHandled("/vote", OutcomeOK)
+		m.VoteCast()
+		m.PollCreated()
+		m.PollClosed()
+		m.ObserveTarantool("GetPoll", time.Now())
+		m.MattermostError("CreatePost")
+		m.Reconnected()
+		m.QueueChanged(1)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := New(reg)
+
+	m.VoteCast()
+	m.VoteCast()
+	m.PollClosed()
+	m.QueueChanged(3)
+	m.QueueChanged(-1)
+	m.ObserveTarantool("GetPoll", time.Now())
+	m.ObserveTarantool("AddVote", time.Now())
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.votes))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.pollsCreated))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.pollsClosed))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.queueDepth))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.tarantoolDuration))
+
+	count, err := testutil.GatherAndCount(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, count)
+}
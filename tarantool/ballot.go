@@ -0,0 +1,228 @@
+package tarantool
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Типы голосований
+const (
+	PollSingle   = "single"   // один вариант
+	PollApproval = "approval" // одобрение любого подмножества вариантов
+	PollScore    = "score"    // оценка каждого варианта по шкале
+	PollText     = "text"     // свободный текстовый ответ
+	PollSchedule = "schedule" // выбор времени встречи: доступность в каждом слоте
+	PollQuiz     = "quiz"     // викторина с правильными вариантами
+)
+
+// Доступность участника в слоте голосования PollSchedule
+const (
+	AvailabilityNo    = 0
+	AvailabilityMaybe = 1 // если очень нужно
+	AvailabilityYes   = 2
+)
+
+// ScoreStats — статистика оценок одного варианта.
+type ScoreStats struct {
+	Count        int
+	Average      float64
+	Median       float64
+	Distribution map[int]int // оценка -> число голосов
+}
+
+// ballotRules описывает, какие бюллетени допустимы в голосовании.
+type ballotRules struct {
+	pollType string
+	options  int
+	scoreMin int
+	scoreMax int
+	multiple bool // можно выбрать несколько вариантов
+}
+
+func (p *Poll) rules() ballotRules {
+	return newBallotRules(p.Type, len(p.Options), p.ScoreMin, p.ScoreMax, p.Correct)
+}
+
+func (r *VoteResult) rules() ballotRules {
+	return newBallotRules(r.Type, len(r.Options), r.ScoreMin, r.ScoreMax, r.Correct)
+}
+
+// newBallotRules разрешает выбор нескольких вариантов в одобрении и в
+// викторинах с несколькими правильными ответами.
+func newBallotRules(pollType string, options, scoreMin, scoreMax int, correct []int) ballotRules {
+	return ballotRules{
+		pollType: pollType,
+		options:  options,
+		scoreMin: scoreMin,
+		scoreMax: scoreMax,
+		multiple: pollType == PollApproval || (pollType == PollQuiz && len(correct) > 1),
+	}
+}
+
+// MultipleChoice сообщает, можно ли выбрать в голосовании несколько вариантов.
+func (p *Poll) MultipleChoice() bool {
+	return p.rules().multiple
+}
+
+// hasValues сообщает, хранит ли бюллетень значение для каждого варианта.
+func (r ballotRules) hasValues() bool {
+	return r.pollType == PollScore || r.pollType == PollSchedule
+}
+
+// EncodeBallot проверяет выбор участника и приводит его к виду, в котором
+// голос хранится в пространстве votes: "2" для одного варианта, "1,3" для
+// одобрения, "1=5,2=3" для оценок и доступности.
+func (p *Poll) EncodeBallot(choices []string) (string, error) {
+	rules := p.rules()
+	ballot, err := rules.parse(choices)
+	if err != nil {
+		return "", err
+	}
+
+	options := make([]int, 0, len(ballot))
+	for option := range ballot {
+		options = append(options, option)
+	}
+	sort.Ints(options)
+
+	parts := make([]string, len(options))
+	for i, option := range options {
+		parts[i] = strconv.Itoa(option)
+		if rules.hasValues() {
+			parts[i] += "=" + strconv.Itoa(ballot[option])
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// DecodeBallot разбирает сохранённый голос: номер варианта -> оценка
+// (для голосований без оценок — 1).
+func (p *Poll) DecodeBallot(option string) (map[int]int, error) {
+	return p.rules().parse([]string{option})
+}
+
+func (r ballotRules) parse(choices []string) (map[int]int, error) {
+	var parts []string
+	for _, choice := range choices {
+		for _, part := range strings.Split(choice, ",") {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+
+	if len(parts) == 0 || (!r.multiple && !r.hasValues() && len(parts) != 1) {
+		return nil, ErrInvalidOption
+	}
+
+	ballot := make(map[int]int, len(parts))
+	for _, part := range parts {
+		value := 1
+		if r.pollType == PollSchedule {
+			optionPart, availabilityPart, ok := strings.Cut(part, "=")
+			value = AvailabilityYes
+			if ok {
+				if value, ok = parseAvailability(availabilityPart); !ok {
+					return nil, ErrInvalidOption
+				}
+			}
+			part = optionPart
+		} else if r.pollType == PollScore {
+			optionPart, scorePart, ok := strings.Cut(part, "=")
+			if !ok {
+				return nil, ErrInvalidOption
+			}
+
+			score, err := strconv.Atoi(scorePart)
+			if err != nil || score < r.scoreMin || score > r.scoreMax {
+				return nil, ErrInvalidOption
+			}
+			part, value = optionPart, score
+		}
+
+		optionNum, err := strconv.Atoi(part)
+		if err != nil || optionNum < 1 || optionNum > r.options {
+			return nil, ErrInvalidOption
+		}
+		if _, dup := ballot[optionNum]; dup {
+			return nil, ErrInvalidOption
+		}
+		ballot[optionNum] = value
+	}
+	return ballot, nil
+}
+
+// IsCorrect сообщает, совпадает ли сохранённый голос с правильными
+// вариантами викторины.
+func (p *Poll) IsCorrect(option string) bool {
+	ballot, err := p.DecodeBallot(option)
+	if err != nil || len(ballot) != len(p.Correct) {
+		return false
+	}
+
+	for _, optionNum := range p.Correct {
+		if _, ok := ballot[optionNum]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAvailability понимает ответы yes/maybe/no, их русские варианты и
+// сокращения +, ?, -.
+func parseAvailability(value string) (int, bool) {
+	switch strings.ToLower(value) {
+	case "yes", "да", "+", "2":
+		return AvailabilityYes, true
+	case "maybe", "ifneedbe", "может", "?", "1":
+		return AvailabilityMaybe, true
+	case "no", "нет", "-", "0":
+		return AvailabilityNo, true
+	}
+	return 0, false
+}
+
+// ParseScale разбирает шкалу оценок вида "0-5" или "1-10".
+func ParseScale(value string) (scoreMin, scoreMax int, err error) {
+	minPart, maxPart, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid scale %q", value)
+	}
+
+	scoreMin, err = strconv.Atoi(minPart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid scale %q", value)
+	}
+	scoreMax, err = strconv.Atoi(maxPart)
+	if err != nil || scoreMin < 0 || scoreMax > 10 || scoreMin >= scoreMax {
+		return 0, 0, fmt.Errorf("invalid scale %q", value)
+	}
+	return scoreMin, scoreMax, nil
+}
+
+func newScoreStats(scores []int) ScoreStats {
+	stats := ScoreStats{Count: len(scores), Distribution: make(map[int]int)}
+	if len(scores) == 0 {
+		return stats
+	}
+
+	sorted := append([]int(nil), scores...)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, score := range sorted {
+		sum += score
+		stats.Distribution[score]++
+	}
+	stats.Average = float64(sum) / float64(len(sorted))
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		stats.Median = float64(sorted[middle])
+	} else {
+		stats.Median = float64(sorted[middle-1]+sorted[middle]) / 2
+	}
+	return stats
+}
@@ -0,0 +1,97 @@
+package tarantool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeBallot(t *testing.T) {
+	single := &Poll{Type: PollSingle, Options: []string{"A", "B"}}
+	approval := &Poll{Type: PollApproval, Options: []string{"A", "B", "C"}}
+	score := &Poll{Type: PollScore, Options: []string{"A", "B"}, ScoreMin: 1, ScoreMax: 10}
+	schedule := &Poll{Type: PollSchedule, Options: []string{"10:00", "14:00", "16:00"}}
+	quiz := &Poll{Type: PollQuiz, Options: []string{"A", "B", "C"}, Correct: []int{2}}
+	multiQuiz := &Poll{Type: PollQuiz, Options: []string{"A", "B", "C"}, Correct: []int{1, 3}}
+
+	tests := []struct {
+		name    string
+		poll    *Poll
+		choices []string
+		want    string
+		wantErr bool
+	}{
+		{name: "single", poll: single, choices: []string{"2"}, want: "2"},
+		{name: "single several", poll: single, choices: []string{"1", "2"}, wantErr: true},
+		{name: "single out of range", poll: single, choices: []string{"3"}, wantErr: true},
+		{name: "approval", poll: approval, choices: []string{"3", "1"}, want: "1,3"},
+		{name: "approval comma separated", poll: approval, choices: []string{"2,3"}, want: "2,3"},
+		{name: "approval duplicate", poll: approval, choices: []string{"1", "1"}, wantErr: true},
+		{name: "score", poll: score, choices: []string{"2=3", "1=10"}, want: "1=10,2=3"},
+		{name: "score below scale", poll: score, choices: []string{"1=0"}, wantErr: true},
+		{name: "score without value", poll: score, choices: []string{"1"}, wantErr: true},
+		{name: "schedule", poll: schedule, choices: []string{"1=yes", "2=?", "3=нет"}, want: "1=2,2=1,3=0"},
+		{name: "schedule yes by default", poll: schedule, choices: []string{"3", "1"}, want: "1=2,3=2"},
+		{name: "schedule unknown answer", poll: schedule, choices: []string{"1=never"}, wantErr: true},
+		{name: "quiz", poll: quiz, choices: []string{"2"}, want: "2"},
+		{name: "quiz several", poll: quiz, choices: []string{"1", "2"}, wantErr: true},
+		{name: "quiz with several correct", poll: multiQuiz, choices: []string{"3", "1"}, want: "1,3"},
+		{name: "empty", poll: approval, choices: []string{","}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.poll.EncodeBallot(tc.choices)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidOption)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDecodeBallot(t *testing.T) {
+	score := &Poll{Type: PollScore, Options: []string{"A", "B"}, ScoreMin: 0, ScoreMax: 5}
+
+	ballot, err := score.DecodeBallot("1=5,2=0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 5, 2: 0}, ballot)
+}
+
+func TestIsCorrect(t *testing.T) {
+	quiz := &Poll{Type: PollQuiz, Options: []string{"A", "B", "C"}, Correct: []int{1, 3}}
+
+	assert.True(t, quiz.IsCorrect("1,3"))
+	assert.False(t, quiz.IsCorrect("1"))
+	assert.False(t, quiz.IsCorrect("1,2,3"))
+	assert.False(t, quiz.IsCorrect("1,2"))
+}
+
+func TestScoreStats(t *testing.T) {
+	stats := newScoreStats([]int{5, 1, 4, 4})
+	assert.Equal(t, 4, stats.Count)
+	assert.Equal(t, 3.5, stats.Average)
+	assert.Equal(t, 4.0, stats.Median)
+	assert.Equal(t, map[int]int{1: 1, 4: 2, 5: 1}, stats.Distribution)
+
+	result := &VoteResult{
+		Type:     PollScore,
+		ScoreMax: 5,
+		Options:  []string{"A", "B"},
+		Ballots: []Vote{
+			{UserID: "alice", Option: "1=5,2=1"},
+			{UserID: "bob", Option: "1=2"},
+		},
+	}
+	result.ApplyWeights(func(userID string) float64 {
+		if userID == "alice" {
+			return 2
+		}
+		return 1
+	})
+	assert.Equal(t, []float64{4, 1}, result.Weighted)
+	assert.Equal(t, 3.0, result.WeightedTotal)
+}
@@ -0,0 +1,29 @@
+package tarantool
+
+import (
+	"log/slog"
+
+	"github.com/tarantool/go-tarantool"
+)
+
+// slogReporter передаёт сообщения драйвера о соединении в slog вместо
+// стандартного log.
+type slogReporter struct {
+	logger *slog.Logger
+}
+
+func (r slogReporter) Report(event tarantool.ConnLogKind, conn *tarantool.Connection, v ...interface{}) {
+	logger := r.logger.With("addr", conn.Addr())
+	switch event {
+	case tarantool.LogReconnectFailed:
+		logger.Warn("Ошибка переподключения к Tarantool", "attempt", v[0], "err", v[1])
+	case tarantool.LogLastReconnectFailed:
+		logger.Error("Попытки переподключения к Tarantool исчерпаны", "err", v[0])
+	case tarantool.LogUnexpectedResultId:
+		if resp, ok := v[0].(*tarantool.Response); ok {
+			logger.Warn("Ответ Tarantool на неизвестный запрос", "request_id", resp.RequestId)
+		}
+	default:
+		logger.Warn("Событие соединения с Tarantool", "event", event, "details", v)
+	}
+}
@@ -0,0 +1,79 @@
+package tarantool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestPollDecoding(t *testing.T) {
+	full := Poll{
+		PollID:       "poll",
+		CreatorID:    "creator",
+		Question:     "Вопрос?",
+		Options:      []string{"A", "B"},
+		Status:       "active",
+		ChannelID:    "channel",
+		PostID:       "post",
+		CreatedAt:    1000,
+		Deadline:     2000,
+		Quorum:       3,
+		Eligible:     10,
+		Type:         PollQuiz,
+		Anonymous:    true,
+		Correct:      []int{2},
+		VoteChange:   VoteChangeLocked,
+		ChangeCutoff: 60,
+		PinResults:   true,
+	}
+
+	tests := []struct {
+		name    string
+		tuple   []interface{}
+		want    Poll
+		wantErr bool
+	}{
+		{
+			name:  "full tuple",
+			tuple: pollTuple(&full),
+			want:  full,
+		},
+		{
+			name:  "tuple before format extensions",
+			tuple: []interface{}{"old", "creator", "Вопрос?", []string{"A", "B"}, "closed"},
+			want: Poll{
+				PollID:    "old",
+				CreatorID: "creator",
+				Question:  "Вопрос?",
+				Options:   []string{"A", "B"},
+				Status:    "closed",
+				Type:      PollSingle,
+			},
+		},
+		{
+			name:    "malformed field",
+			tuple:   []interface{}{"poll", "creator", "Вопрос?", "not options", "active"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := msgpack.Marshal(tc.tuple)
+			require.NoError(t, err)
+
+			var poll Poll
+			err = msgpack.Unmarshal(data, &poll)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			poll.setDefaults()
+			assert.Equal(t, tc.want, poll)
+		})
+	}
+}
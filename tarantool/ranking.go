@@ -0,0 +1,82 @@
+package tarantool
+
+import (
+	"math"
+	"sort"
+)
+
+// rankEpsilon — допуск при сравнении взвешенных итогов.
+const rankEpsilon = 1e-9
+
+// Rank заполняет проценты, порядок мест, победителей и отрыв лидера.
+// Варианты сравниваются по голосам, для оценок — по средней оценке;
+// если заданы веса, используются взвешенные итоги. Вызывается повторно
+// после ApplyWeights.
+func (r *VoteResult) Rank() {
+	r.Percentages, r.Ranking, r.Winners, r.Tie, r.Margin = nil, nil, nil, false, 0
+	if r.Type == PollSchedule || len(r.Options) == 0 || len(r.Votes) != len(r.Options) {
+		return
+	}
+
+	values := make([]float64, len(r.Options))
+	counted := make([]bool, len(r.Options))
+	for i := range r.Options {
+		switch {
+		case r.Type == PollScore && r.Weighted != nil:
+			values[i] = r.Weighted[i]
+		case r.Type == PollScore && i < len(r.Scores):
+			values[i] = r.Scores[i].Average
+		case r.Weighted != nil:
+			values[i] = r.Weighted[i]
+		default:
+			values[i] = float64(r.Votes[i])
+		}
+		counted[i] = r.Votes[i] > 0
+		if r.Type == PollScore && !counted[i] {
+			// Вариант без оценок уходит в конец и не влияет на отрыв
+			values[i] = math.Inf(-1)
+		}
+	}
+
+	if r.Type != PollScore {
+		total := float64(r.Total)
+		if r.Weighted != nil {
+			total = r.WeightedTotal
+		}
+
+		r.Percentages = make([]float64, len(r.Options))
+		for i := range values {
+			if total > 0 {
+				r.Percentages[i] = values[i] * 100 / total
+			}
+		}
+	}
+
+	r.Ranking = make([]int, len(r.Options))
+	for i := range r.Ranking {
+		r.Ranking[i] = i + 1
+	}
+	sort.SliceStable(r.Ranking, func(i, j int) bool {
+		return values[r.Ranking[i]-1] > values[r.Ranking[j]-1]+rankEpsilon
+	})
+
+	leader := r.Ranking[0] - 1
+	if !counted[leader] || (r.Type != PollScore && values[leader] <= 0) {
+		return
+	}
+
+	for _, optionNum := range r.Ranking {
+		if math.Abs(values[optionNum-1]-values[leader]) > rankEpsilon {
+			if counted[optionNum-1] || r.Type != PollScore {
+				r.Margin = values[leader] - values[optionNum-1]
+			}
+			break
+		}
+		r.Winners = append(r.Winners, optionNum)
+	}
+
+	r.Tie = len(r.Winners) > 1
+	if r.Tie {
+		r.Margin = 0
+	}
+}
@@ -0,0 +1,89 @@
+package tarantool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      VoteResult
+		percentages []float64
+		ranking     []int
+		winners     []int
+		tie         bool
+		margin      float64
+	}{
+		{
+			name:        "clear winner",
+			result:      VoteResult{Options: []string{"A", "B", "C"}, Votes: []int{1, 5, 2}, Total: 8},
+			percentages: []float64{12.5, 62.5, 25},
+			ranking:     []int{2, 3, 1},
+			winners:     []int{2},
+			margin:      3,
+		},
+		{
+			name:        "tie",
+			result:      VoteResult{Options: []string{"A", "B", "C"}, Votes: []int{3, 1, 3}, Total: 7},
+			percentages: []float64{300.0 / 7, 100.0 / 7, 300.0 / 7},
+			ranking:     []int{1, 3, 2},
+			winners:     []int{1, 3},
+			tie:         true,
+		},
+		{
+			name:        "no votes",
+			result:      VoteResult{Options: []string{"A", "B"}, Votes: []int{0, 0}},
+			percentages: []float64{0, 0},
+			ranking:     []int{1, 2},
+		},
+		{
+			name: "weighted",
+			result: VoteResult{
+				Options:       []string{"A", "B"},
+				Votes:         []int{1, 2},
+				Total:         3,
+				Weighted:      []float64{3, 2},
+				WeightedTotal: 5,
+			},
+			percentages: []float64{60, 40},
+			ranking:     []int{1, 2},
+			winners:     []int{1},
+			margin:      1,
+		},
+		{
+			name: "score",
+			result: VoteResult{
+				Type:    PollScore,
+				Options: []string{"A", "B", "C"},
+				Votes:   []int{2, 0, 2},
+				Total:   2,
+				Scores:  []ScoreStats{{Count: 2, Average: 3}, {}, {Count: 2, Average: 4.5}},
+			},
+			ranking: []int{3, 1, 2},
+			winners: []int{3},
+			margin:  1.5,
+		},
+		{
+			name: "schedule",
+			result: VoteResult{
+				Type:    PollSchedule,
+				Options: []string{"slot"},
+				Votes:   []int{1},
+				Total:   1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.result.Rank()
+			assert.InDeltaSlice(t, tc.percentages, tc.result.Percentages, 1e-9)
+			assert.Equal(t, tc.ranking, tc.result.Ranking)
+			assert.Equal(t, tc.winners, tc.result.Winners)
+			assert.Equal(t, tc.tie, tc.result.Tie)
+			assert.Equal(t, tc.margin, tc.result.Margin)
+		})
+	}
+}
@@ -58,6 +58,330 @@ box.once('init', function()
     print("[INIT] Database schema initialized")
 end)
 
+-- Канал, пост, время создания и дедлайн голосования; пространство напоминаний
+box.once('reminders', function()
+    box.space.polls:format({
+        {name = 'poll_id', type = 'string'},
+        {name = 'creator_id', type = 'string'},
+        {name = 'question', type = 'string'},
+        {name = 'options', type = 'array'},
+        {name = 'status', type = 'string'},
+        {name = 'channel_id', type = 'string', is_nullable = true},
+        {name = 'post_id', type = 'string', is_nullable = true},
+        {name = 'created_at', type = 'unsigned', is_nullable = true},
+        {name = 'deadline', type = 'unsigned', is_nullable = true}
+    })
+
+    box.schema.space.create("reminders", {
+        format = {
+            {name = "poll_id", type = "string"},
+            {name = "remind_at", type = "unsigned"}
+        }
+    })
+    box.space.reminders:create_index("primary", {
+        parts = {"poll_id", "remind_at"},
+        unique = true
+    })
+    -- Индекс для выборки наступивших напоминаний
+    box.space.reminders:create_index("remind_at_idx", {
+        parts = {"remind_at"},
+        unique = false
+    })
+    print("[INIT] Space 'reminders' created")
+end)
+
+-- Кворум голосования и индекс для автоматического закрытия по сроку
+box.once('quorum', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'quorum', type = 'unsigned', is_nullable = true})
+    table.insert(format, {name = 'eligible', type = 'unsigned', is_nullable = true})
+    box.space.polls:format(format)
+
+    box.space.polls:create_index('status_deadline_idx', {
+        parts = {{'status'}, {'deadline', is_nullable = true}},
+        unique = false
+    })
+    print("[INIT] Poll quorum fields created")
+end)
+
+-- Веса голосов участников и групп для голосования или канала
+box.once('weights', function()
+    box.schema.space.create("weights", {
+        format = {
+            {name = "scope", type = "string"},
+            {name = "subject", type = "string"},
+            {name = "weight", type = "number"},
+            {name = "name", type = "string"}
+        }
+    })
+    box.space.weights:create_index("primary", {
+        parts = {"scope", "subject"},
+        unique = true
+    })
+    print("[INIT] Space 'weights' created")
+end)
+
+-- Передача голоса другому участнику в голосовании или во всём канале
+box.once('delegations', function()
+    box.schema.space.create("delegations", {
+        format = {
+            {name = "scope", type = "string"},
+            {name = "from_user", type = "string"},
+            {name = "to_user", type = "string"}
+        }
+    })
+    box.space.delegations:create_index("primary", {
+        parts = {"scope", "from_user"},
+        unique = true
+    })
+    print("[INIT] Space 'delegations' created")
+end)
+
+-- Тип голосования и шкала оценок
+box.once('poll_types', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'type', type = 'string', is_nullable = true})
+    table.insert(format, {name = 'score_min', type = 'unsigned', is_nullable = true})
+    table.insert(format, {name = 'score_max', type = 'unsigned', is_nullable = true})
+    box.space.polls:format(format)
+    print("[INIT] Poll types created")
+end)
+
+-- Голосования со свободными ответами
+box.once('answers', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'anonymous', type = 'boolean', is_nullable = true})
+    box.space.polls:format(format)
+
+    box.schema.space.create("answers", {
+        format = {
+            {name = "poll_id", type = "string"},
+            {name = "user_id", type = "string"},
+            {name = "text", type = "string"},
+            {name = "created_at", type = "unsigned"}
+        }
+    })
+    box.space.answers:create_index("primary", {
+        parts = {"poll_id", "user_id"},
+        unique = true
+    })
+    print("[INIT] Space 'answers' created")
+end)
+
+-- Викторины: правильные варианты и итоги участников для рейтинга канала
+box.once('quizzes', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'correct', type = 'array', is_nullable = true})
+    box.space.polls:format(format)
+
+    box.schema.space.create("quiz_results", {
+        format = {
+            {name = "poll_id", type = "string"},
+            {name = "user_id", type = "string"},
+            {name = "channel_id", type = "string"},
+            {name = "correct", type = "boolean"}
+        }
+    })
+    box.space.quiz_results:create_index("primary", {
+        parts = {"poll_id", "user_id"},
+        unique = true
+    })
+    box.space.quiz_results:create_index("channel_idx", {
+        parts = {"channel_id"},
+        unique = false
+    })
+    print("[INIT] Space 'quiz_results' created")
+end)
+
+-- Поиск голосования по посту для голосования реакциями
+box.once('reactions', function()
+    box.space.polls:create_index('post_idx', {
+        parts = {{'post_id', is_nullable = true}},
+        unique = false
+    })
+    print("[INIT] Poll post index created")
+end)
+
+-- Политика изменения голоса
+box.once('vote_change', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'vote_change', type = 'string', is_nullable = true})
+    table.insert(format, {name = 'change_cutoff', type = 'unsigned', is_nullable = true})
+    box.space.polls:format(format)
+    print("[INIT] Poll vote change policy created")
+end)
+
+-- Шаблоны голосований: снимок кортежа polls под именем
+box.once('templates', function()
+    box.schema.space.create("templates", {
+        format = {
+            {name = "name", type = "string"},
+            {name = "creator_id", type = "string"},
+            {name = "poll", type = "array"}
+        }
+    })
+    box.space.templates:create_index("primary", {
+        parts = {"name"},
+        unique = true
+    })
+    print("[INIT] Space 'templates' created")
+end)
+
+-- Регулярные голосования по cron-расписанию
+box.once('recurrences', function()
+    box.schema.space.create("recurrences", {
+        format = {
+            {name = "id", type = "string"},
+            {name = "channel_id", type = "string"},
+            {name = "creator_id", type = "string"},
+            {name = "cron", type = "string"},
+            {name = "timezone", type = "string"},
+            {name = "template", type = "string"},
+            {name = "next_run", type = "unsigned"}
+        }
+    })
+    box.space.recurrences:create_index("primary", {
+        parts = {"id"},
+        unique = true
+    })
+    box.space.recurrences:create_index("channel_idx", {
+        parts = {"channel_id"},
+        unique = false
+    })
+    -- Индекс для выборки наступивших запусков
+    box.space.recurrences:create_index("next_run_idx", {
+        parts = {"next_run"},
+        unique = false
+    })
+    print("[INIT] Space 'recurrences' created")
+end)
+
+-- Атомарно переносит запуск регулярного голосования, чтобы при нескольких
+-- репликах бота голосование создала только одна из них
+function claim_recurrence(id, expected, next_run)
+    local recurrence = box.space.recurrences:get(id)
+    if recurrence == nil or recurrence.next_run ~= expected then
+        return false
+    end
+    box.space.recurrences:update(id, {{'=', 'next_run', next_run}})
+    return true
+end
+
+-- Атомарно закрывает активное голосование, чтобы итоги объявил только
+-- тот, кто его закрыл: /endpoll или планировщик одной из реплик
+function close_poll(poll_id)
+    local poll = box.space.polls:get(poll_id)
+    if poll == nil or poll.status ~= 'active' then
+        return false
+    end
+    box.space.polls:update(poll_id, {{'=', 'status', 'closed'}})
+    return true
+end
+
+-- Время подачи голоса для выгрузки результатов
+box.once('vote_times', function()
+    local format = box.space.votes:format()
+    table.insert(format, {name = 'voted_at', type = 'unsigned', is_nullable = true})
+    box.space.votes:format(format)
+    print("[INIT] Vote timestamps created")
+end)
+
+-- Зафиксированные итоги завершённых голосований
+box.once('final_results', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'pin_results', type = 'boolean', is_nullable = true})
+    box.space.polls:format(format)
+
+    box.schema.space.create("final_results", {
+        format = {
+            {name = "poll_id", type = "string"},
+            {name = "summary", type = "string"},
+            {name = "post_id", type = "string"},
+            {name = "closed_at", type = "unsigned"}
+        }
+    })
+    box.space.final_results:create_index("primary", {
+        parts = {"poll_id"},
+        unique = true
+    })
+    print("[INIT] Space 'final_results' created")
+end)
+
+-- Обработанные команды и позиция чтения каналов для догонки после обрыва
+box.once('processed_posts', function()
+    box.schema.space.create("processed_posts", {
+        format = {
+            {name = "post_id", type = "string"},
+            {name = "channel_id", type = "string"},
+            {name = "processed_at", type = "unsigned"}
+        }
+    })
+    box.space.processed_posts:create_index("primary", {
+        parts = {"post_id"},
+        unique = true
+    })
+    -- Индекс для очистки старых записей
+    box.space.processed_posts:create_index("processed_at_idx", {
+        parts = {"processed_at"},
+        unique = false
+    })
+
+    box.schema.space.create("channel_cursors", {
+        format = {
+            {name = "channel_id", type = "string"},
+            {name = "last_post_at", type = "unsigned"}
+        }
+    })
+    box.space.channel_cursors:create_index("primary", {
+        parts = {"channel_id"},
+        unique = true
+    })
+    print("[INIT] Spaces 'processed_posts' and 'channel_cursors' created")
+end)
+
+-- Сдвигает позицию чтения канала только вперёд
+function advance_cursor(channel_id, last_post_at)
+    local cursor = box.space.channel_cursors:get(channel_id)
+    if cursor == nil or cursor.last_post_at < last_post_at then
+        box.space.channel_cursors:replace({channel_id, last_post_at})
+    end
+end
+
+-- Атомарно отмечает пост обработанным, чтобы команда выполнилась один раз,
+-- даже если пришла и по websocket, и при догонке
+function claim_post(post_id, channel_id, created_at)
+    if box.space.processed_posts:get(post_id) ~= nil then
+        return false
+    end
+    box.space.processed_posts:insert({post_id, channel_id, os.time()})
+    advance_cursor(channel_id, created_at)
+    return true
+end
+
+-- Записи об обработанных постах нужны только на время догонки
+require('fiber').create(function()
+    while true do
+        local expired = os.time() - 7 * 24 * 60 * 60
+        local tuples = box.space.processed_posts.index.processed_at_idx:select(expired, {iterator = 'LT'})
+        for _, tuple in ipairs(tuples) do
+            box.space.processed_posts:delete(tuple.post_id)
+        end
+        require('fiber').sleep(60 * 60)
+    end
+end)
+
+-- Кворум в процентах, чтобы копии голосований пересчитывали его для своего канала
+box.once('quorum_percent', function()
+    local format = box.space.polls:format()
+    table.insert(format, {name = 'quorum_percent', type = 'unsigned', is_nullable = true})
+    box.space.polls:format(format)
+    print("[INIT] Poll quorum percent created")
+end)
+
+-- Версия схемы для проверки готовности бота (/readyz): увеличивается вместе
+-- с SchemaVersion в tarantool.go при добавлении миграции
+box.space._schema:replace({'voting_bot_schema_version', 17})
+
 -- Фоновый fiber для мониторинга
 fiber = require('fiber')
 fiber.create(function()
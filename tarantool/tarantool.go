@@ -4,29 +4,83 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/tarantool/go-tarantool"
+	"voting-bot/metrics"
 )
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrInvalidOption = errors.New("invalid option")
+	ErrPollClosed    = errors.New("poll closed")
+	ErrVoteLocked    = errors.New("vote cannot be changed")
+	ErrChangeClosed  = errors.New("vote change window closed")
+)
+
+// Политики изменения голоса
+const (
+	VoteChangeAllowed = ""       // голос можно менять и отзывать
+	VoteChangeLocked  = "locked" // первый голос окончательный
+	VoteChangeUntil   = "until"  // менять можно до ChangeCutoff секунд до срока
 )
 
 type Client interface {
-	CreatePoll(ctx context.Context, pollID, creatorID, question string, options []string) error
+	CreatePoll(ctx context.Context, poll *Poll) error
 	GetPoll(ctx context.Context, pollID string) (*Poll, error)
+	GetPollByPost(ctx context.Context, postID string) (*Poll, error)
+	SetPollPost(ctx context.Context, pollID, postID string) error
 	AddVote(ctx context.Context, pollID, userID, option string) error
+	GetVote(ctx context.Context, pollID, userID string) (*Vote, error)
+	DeleteVote(ctx context.Context, pollID, userID string) error
+	GetVoters(ctx context.Context, pollID string) ([]string, error)
 	GetResults(ctx context.Context, pollID string) (*VoteResult, error)
 	UpdatePollStatus(ctx context.Context, pollID, status string) error
+	ClosePoll(ctx context.Context, pollID string) (bool, error)
 	DeletePoll(ctx context.Context, pollID string) error
+	AddReminder(ctx context.Context, pollID string, remindAt int64) error
+	GetDueReminders(ctx context.Context, now int64) ([]Reminder, error)
+	ClaimReminder(ctx context.Context, pollID string, remindAt int64) (bool, error)
+	GetExpiredPolls(ctx context.Context, now int64) ([]*Poll, error)
+	GetVotes(ctx context.Context, pollID string) ([]Vote, error)
+	SetWeight(ctx context.Context, weight Weight) error
+	DeleteWeight(ctx context.Context, scope, subject string) error
+	GetWeights(ctx context.Context, scope string) ([]Weight, error)
+	SetDelegation(ctx context.Context, delegation Delegation) error
+	DeleteDelegation(ctx context.Context, scope, fromUser string) error
+	GetDelegations(ctx context.Context, scope string) ([]Delegation, error)
+	AddAnswer(ctx context.Context, answer Answer) error
+	GetAnswers(ctx context.Context, pollID string) ([]Answer, error)
+	SaveTemplate(ctx context.Context, template Template) error
+	GetTemplate(ctx context.Context, name string) (*Template, error)
+	GetTemplates(ctx context.Context) ([]Template, error)
+	SaveQuizResult(ctx context.Context, result QuizResult) error
+	GetQuizResults(ctx context.Context, channelID string) ([]QuizResult, error)
+	SaveFinalResult(ctx context.Context, result FinalResult) error
+	GetFinalResult(ctx context.Context, pollID string) (*FinalResult, error)
+	AddRecurrence(ctx context.Context, recurrence Recurrence) error
+	GetRecurrence(ctx context.Context, id string) (*Recurrence, error)
+	GetRecurrences(ctx context.Context, channelID string) ([]Recurrence, error)
+	GetDueRecurrences(ctx context.Context, now int64) ([]Recurrence, error)
+	ClaimRecurrence(ctx context.Context, id string, expected, next int64) (bool, error)
+	DeleteRecurrence(ctx context.Context, id string) error
+	ClaimPost(ctx context.Context, postID, channelID string, createdAt int64) (bool, error)
+	GetChannelCursor(ctx context.Context, channelID string) (int64, error)
+	AdvanceChannelCursor(ctx context.Context, channelID string, lastPostAt int64) error
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (int64, error)
 	Close() error
 }
 
 type TarantoolClient struct {
 	conn *tarantool.Connection
+	// metrics учитывает каждый запрос к Tarantool один раз, под именем
+	// метода, который его отправил. Составные методы (AddVote, DeleteVote,
+	// GetResults) замеряют только свои запросы, а не вызовы GetPoll и
+	// других методов, которые учитываются сами.
+	metrics *metrics.Metrics
 }
 
 type Poll struct {
@@ -34,24 +88,225 @@ type Poll struct {
 	CreatorID string   `msgpack:"creator_id"`
 	Question  string   `msgpack:"question"`
 	Options   []string `msgpack:"options"`
-	CreatedAt int64    `msgpack:"created_at"`
 	Status    string   `msgpack:"status"`
+	ChannelID string   `msgpack:"channel_id"`
+	PostID    string   `msgpack:"post_id"`
+	CreatedAt int64    `msgpack:"created_at"`
+	Deadline  int64    `msgpack:"deadline"` // unix-время окончания, 0 — без срока
+	Quorum    int      `msgpack:"quorum"`   // минимальное число голосов, 0 — без кворума
+	Eligible  int      `msgpack:"eligible"` // участников канала на момент создания
+	Type      string   `msgpack:"type"`     // PollSingle, PollApproval, PollScore, PollText, PollSchedule или PollQuiz
+	ScoreMin  int      `msgpack:"score_min"`
+	ScoreMax  int      `msgpack:"score_max"`
+	Anonymous bool     `msgpack:"anonymous"` // ответы публикуются без авторов
+	Correct   []int    `msgpack:"correct"`   // номера правильных вариантов викторины
+	// VoteChange — политика изменения голоса: VoteChangeAllowed, VoteChangeLocked или VoteChangeUntil
+	VoteChange   string `msgpack:"vote_change"`
+	ChangeCutoff int64  `msgpack:"change_cutoff"` // секунд до срока, после которых голос не меняется
+	PinResults   bool   `msgpack:"pin_results"`   // закрепить итоги в канале после завершения
+	// QuorumPercent — кворум в процентах участников канала, 0 — кворум задан числом голосов
+	QuorumPercent int `msgpack:"quorum_percent"`
+}
+
+// FinalResult — итоги, опубликованные при завершении голосования. Запись
+// не перезаписывается, поэтому опубликованный исход не меняется задним числом.
+type FinalResult struct {
+	PollID   string
+	Summary  string
+	PostID   string
+	ClosedAt int64
+}
+
+// Template — сохранённое под именем голосование для повторного запуска.
+type Template struct {
+	Name      string
+	CreatorID string
+	Poll      *Poll // снимок голосования: вопрос, варианты и настройки
+}
+
+// Recurrence — регулярный запуск шаблона в канале по cron-расписанию.
+type Recurrence struct {
+	ID        string
+	ChannelID string
+	CreatorID string
+	Cron      string
+	Timezone  string // часовой пояс, в котором вычисляется расписание
+	Template  string
+	NextRun   int64 // unix-время следующего запуска
+}
+
+// QuizResult — итог участника в завершённой викторине, из них строится
+// рейтинг канала.
+type QuizResult struct {
+	PollID    string `msgpack:"poll_id"`
+	UserID    string `msgpack:"user_id"`
+	ChannelID string `msgpack:"channel_id"`
+	Correct   bool   `msgpack:"correct"`
+}
+
+// Answer — свободный ответ участника в голосовании типа PollText.
+type Answer struct {
+	PollID    string `msgpack:"poll_id"`
+	UserID    string `msgpack:"user_id"`
+	Text      string `msgpack:"text"`
+	CreatedAt int64  `msgpack:"created_at"`
+}
+
+// Reminder — запланированное напоминание неголосовавшим участникам.
+type Reminder struct {
+	PollID   string `msgpack:"poll_id"`
+	RemindAt int64  `msgpack:"remind_at"`
+}
+
+type Vote struct {
+	PollID    string `msgpack:"poll_id"`
+	UserID    string `msgpack:"user_id"`
+	Option    string `msgpack:"option_id"`
+	VotedAt   int64  `msgpack:"voted_at"`
+	Delegated bool   `msgpack:"-"` // голос засчитан по доверенности
+}
+
+// Delegation — передача голоса FromUser участнику ToUser в голосовании
+// или во всех голосованиях канала (Scope — ID голосования или канала).
+type Delegation struct {
+	Scope    string `msgpack:"scope"`
+	FromUser string `msgpack:"from_user"`
+	ToUser   string `msgpack:"to_user"`
+}
+
+// Weight — вес голоса участника или группы в рамках голосования либо канала.
+// Scope — ID голосования или канала, Subject — "user:ID" или "group:ID".
+type Weight struct {
+	Scope   string  `msgpack:"scope"`
+	Subject string  `msgpack:"subject"`
+	Value   float64 `msgpack:"weight"`
+	Name    string  `msgpack:"name"`
 }
 
 type VoteResult struct {
-	Question string
-	Options  []string
-	Votes    []int
-	Total    int
+	Question      string
+	Status        string
+	Type          string
+	ScoreMin      int
+	ScoreMax      int
+	Options       []string
+	Correct       []int  // правильные варианты викторины
+	Ballots       []Vote // учтённые голоса, включая поданные по доверенности
+	Votes         []int  // голосов (для оценок — число оценок) за каждый вариант
+	Total         int    // число учтённых бюллетеней
+	Delegated     int
+	Scores        []ScoreStats // только для голосований с оценками и выбора времени
+	Weighted      []float64    // nil, если веса не настроены; для оценок — средневзвешенная оценка
+	WeightedTotal float64
+	Quorum        int
+	Eligible      int
+	CorrectVotes  int       // правильных ответов в викторине
+	Percentages   []float64 // доля каждого варианта в процентах; nil для оценок и выбора времени
+	Ranking       []int     // номера вариантов от лучшего результата к худшему
+	Winners       []int     // варианты с лучшим результатом, пусто без голосов
+	Tie           bool      // лучший результат разделили несколько вариантов
+	Margin        float64   // отрыв победителя от следующего места
+}
+
+// ApplyWeights подсчитывает взвешенные итоги по учтённым голосам.
+func (r *VoteResult) ApplyWeights(weight func(userID string) float64) {
+	r.Weighted = make([]float64, len(r.Options))
+	r.WeightedTotal = 0
+
+	// Для оценок копится сумма весов по каждому варианту
+	weightSums := make([]float64, len(r.Options))
+	for _, vote := range r.Ballots {
+		ballot, err := r.rules().parse([]string{vote.Option})
+		if err != nil {
+			continue
+		}
+
+		w := weight(vote.UserID)
+		for optionNum, value := range ballot {
+			r.Weighted[optionNum-1] += w * float64(value)
+			weightSums[optionNum-1] += w
+		}
+		r.WeightedTotal += w
+	}
+
+	if r.Type == PollScore {
+		for i := range r.Weighted {
+			if weightSums[i] > 0 {
+				r.Weighted[i] /= weightSums[i]
+			}
+		}
+	}
+
+	r.Rank()
+}
+
+// QuorumReached сообщает, набрано ли голосование необходимое число голосов.
+func (r *VoteResult) QuorumReached() bool {
+	return r.Total >= r.Quorum
+}
+
+// CanChangeVote проверяет, можно ли в момент now изменить или отозвать уже
+// поданный голос.
+func (p *Poll) CanChangeVote(now time.Time) error {
+	switch p.VoteChange {
+	case VoteChangeLocked:
+		return ErrVoteLocked
+	case VoteChangeUntil:
+		if p.Deadline > 0 && now.Unix() >= p.Deadline-p.ChangeCutoff {
+			return ErrChangeClosed
+		}
+	}
+	return nil
+}
+
+// SchemaVersion — версия схемы, которую ожидает бот. Совпадает с
+// voting_bot_schema_version в tarantool-config.lua.
+const SchemaVersion = 17
+
+const schemaVersionKey = "voting_bot_schema_version"
+
+// Параметры соединения с Tarantool по умолчанию.
+const (
+	DefaultTimeout       = 10 * time.Second
+	DefaultReconnect     = 5 * time.Second
+	DefaultMaxReconnects = 5
+)
+
+// Options — параметры соединения с Tarantool.
+type Options struct {
+	User          string
+	Password      string
+	Timeout       time.Duration    // таймаут запроса
+	Reconnect     time.Duration    // пауза между попытками переподключения
+	MaxReconnects uint             // попыток переподключения, 0 — без ограничения
+	Logger        *slog.Logger     // журнал соединения, по умолчанию slog.Default()
+	Metrics       *metrics.Metrics // метрики запросов, nil — без метрик
 }
 
 func NewTarantoolClient(address, user, password string) (*TarantoolClient, error) {
-	opts := tarantool.Opts{
+	return Connect(address, Options{
 		User:          user,
-		Pass:          password,
-		Timeout:       10 * time.Second,
-		Reconnect:     5 * time.Second,
-		MaxReconnects: 5,
+		Password:      password,
+		Timeout:       DefaultTimeout,
+		Reconnect:     DefaultReconnect,
+		MaxReconnects: DefaultMaxReconnects,
+	})
+}
+
+// Connect подключается к Tarantool с заданными параметрами и проверяет
+// соединение.
+func Connect(address string, options Options) (*TarantoolClient, error) {
+	logger := options.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	opts := tarantool.Opts{
+		User:          options.User,
+		Pass:          options.Password,
+		Timeout:       options.Timeout,
+		Reconnect:     options.Reconnect,
+		MaxReconnects: options.MaxReconnects,
+		Logger:        slogReporter{logger: logger},
 	}
 
 	conn, err := tarantool.Connect(address, opts)
@@ -59,131 +314,877 @@ func NewTarantoolClient(address, user, password string) (*TarantoolClient, error
 		return nil, fmt.Errorf("connection error: %w", err)
 	}
 
-	if _, err := conn.Ping(); err != nil {
+	tc := &TarantoolClient{conn: conn, metrics: options.Metrics}
+	if err := tc.Ping(context.Background()); err != nil {
 		return nil, fmt.Errorf("ping failed: %w", err)
 	}
+	return tc, nil
+}
 
-	return &TarantoolClient{conn: conn}, nil
+// Ping проверяет, что Tarantool отвечает на запросы. Ожидание ответа
+// прерывается при отмене ctx, не дожидаясь таймаута соединения.
+func (tc *TarantoolClient) Ping(ctx context.Context) error {
+	defer tc.metrics.ObserveTarantool("Ping", time.Now())
+	_, err := tc.conn.Do(tarantool.NewPingRequest().Context(ctx)).Get()
+	return err
 }
 
-func (tc *TarantoolClient) CreatePoll(pollID, creatorID, question string, options []string) error {
-	_, err := tc.conn.Insert("polls", []interface{}{
-		pollID,
-		creatorID,
-		question,
-		options,
-		"active",
-	})
+// GetSchemaVersion возвращает версию схемы, записанную tarantool-config.lua;
+// 0 — версия не записана. Как и Ping, учитывает отмену ctx.
+func (tc *TarantoolClient) GetSchemaVersion(ctx context.Context) (int64, error) {
+	defer tc.metrics.ObserveTarantool("GetSchemaVersion", time.Now())
+	req := tarantool.NewSelectRequest("_schema").
+		Index("primary").
+		Limit(1).
+		Iterator(tarantool.IterEq).
+		Key([]interface{}{schemaVersionKey}).
+		Context(ctx)
+	resp, err := tc.conn.Do(req).Get()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp.Data) == 0 {
+		return 0, nil
+	}
+	return intField(resp.Tuples()[0], 1), nil
+}
+
+func (tc *TarantoolClient) CreatePoll(ctx context.Context, poll *Poll) error {
+	defer tc.metrics.ObserveTarantool("CreatePoll", time.Now())
+	active := *poll
+	active.Status = "active"
+	if active.Type == "" {
+		active.Type = PollSingle
+	}
+
+	_, err := tc.conn.Insert("polls", pollTuple(&active))
 	return err
 }
 
-func (tc *TarantoolClient) GetPoll(pollID string) (*Poll, error) {
-	resp, err := tc.conn.Select("polls", "primary", 0, 1, tarantool.IterEq, []interface{}{pollID})
+// pollTuple раскладывает голосование по полям пространства polls.
+func pollTuple(poll *Poll) []interface{} {
+	return []interface{}{
+		poll.PollID,
+		poll.CreatorID,
+		poll.Question,
+		poll.Options,
+		poll.Status,
+		poll.ChannelID,
+		poll.PostID,
+		poll.CreatedAt,
+		poll.Deadline,
+		poll.Quorum,
+		poll.Eligible,
+		poll.Type,
+		poll.ScoreMin,
+		poll.ScoreMax,
+		poll.Anonymous,
+		poll.Correct,
+		poll.VoteChange,
+		poll.ChangeCutoff,
+		poll.PinResults,
+		poll.QuorumPercent,
+	}
+}
+
+func (tc *TarantoolClient) GetPoll(ctx context.Context, pollID string) (*Poll, error) {
+	defer tc.metrics.ObserveTarantool("GetPoll", time.Now())
+	polls, err := tc.selectPolls("primary", 1, tarantool.IterEq, []interface{}{pollID})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(resp.Data) == 0 {
+	if len(polls) == 0 {
 		return nil, ErrNotFound
 	}
 
-	data := resp.Data[0].([]interface{})
-	return &Poll{
-		PollID:    data[0].(string),
-		CreatorID: data[1].(string),
-		Question:  data[2].(string),
-		Options:   convertToStringSlice(data[3].([]interface{})),
-		Status:    data[4].(string),
-	}, nil
+	return &polls[0], nil
 }
 
-func (tc *TarantoolClient) AddVote(pollID, userID, option string) error {
-	poll, err := tc.GetPoll(pollID)
+// GetPollByPost находит голосование по посту, в котором бот его опубликовал.
+func (tc *TarantoolClient) GetPollByPost(ctx context.Context, postID string) (*Poll, error) {
+	defer tc.metrics.ObserveTarantool("GetPollByPost", time.Now())
+	polls, err := tc.selectPolls("post_idx", 1, tarantool.IterEq, []interface{}{postID})
 	if err != nil {
+		return nil, err
+	}
+
+	if len(polls) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &polls[0], nil
+}
+
+// selectPolls читает голосования, раскладывая кортежи по полям Poll в порядке
+// объявления. Кортеж неверного формата даёт ошибку, а не панику.
+func (tc *TarantoolClient) selectPolls(index string, limit, iterator uint32, key []interface{}) ([]Poll, error) {
+	var polls []Poll
+	if err := tc.conn.SelectTyped("polls", index, 0, limit, iterator, key, &polls); err != nil {
+		return nil, err
+	}
+
+	for i := range polls {
+		polls[i].setDefaults()
+	}
+	return polls, nil
+}
+
+func (tc *TarantoolClient) SetPollPost(ctx context.Context, pollID, postID string) error {
+	defer tc.metrics.ObserveTarantool("SetPollPost", time.Now())
+	_, err := tc.conn.Update("polls", "primary", []interface{}{pollID}, []interface{}{
+		[]interface{}{"=", 6, postID},
+	})
+	return err
+}
+
+func (tc *TarantoolClient) AddVote(ctx context.Context, pollID, userID, option string) error {
+	poll, err := tc.GetPoll(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	if poll.Status != "active" {
+		return ErrPollClosed
+	}
+
+	if _, err := poll.DecodeBallot(option); err != nil {
 		return err
 	}
 
-	optionNum, err := strconv.Atoi(option)
-	if err != nil || optionNum < 1 || optionNum > len(poll.Options) {
-		return ErrInvalidOption
+	current, err := tc.GetVote(ctx, pollID, userID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return err
+	case current.Option == option:
+		return nil
+	default:
+		if err := poll.CanChangeVote(time.Now()); err != nil {
+			return err
+		}
 	}
 
+	defer tc.metrics.ObserveTarantool("AddVote", time.Now())
 	_, err = tc.conn.Replace("votes", []interface{}{
 		pollID,
 		userID,
 		option,
+		time.Now().Unix(),
 	})
 	return err
 }
 
-func (tc *TarantoolClient) GetResults(pollID string) (*VoteResult, error) {
-	poll, err := tc.GetPoll(pollID)
+func (tc *TarantoolClient) GetVote(ctx context.Context, pollID, userID string) (*Vote, error) {
+	defer tc.metrics.ObserveTarantool("GetVote", time.Now())
+	resp, err := tc.conn.Select("votes", "primary", 0, 1, tarantool.IterEq, []interface{}{pollID, userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, ErrNotFound
+	}
+
+	tuple := resp.Tuples()[0]
+	return &Vote{
+		PollID:  stringField(tuple, 0),
+		UserID:  stringField(tuple, 1),
+		Option:  stringField(tuple, 2),
+		VotedAt: intField(tuple, 3),
+	}, nil
+}
+
+// DeleteVote отзывает голос участника с учётом политики изменения голоса.
+func (tc *TarantoolClient) DeleteVote(ctx context.Context, pollID, userID string) error {
+	poll, err := tc.GetPoll(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	if poll.Status != "active" {
+		return ErrPollClosed
+	}
+
+	if _, err := tc.GetVote(ctx, pollID, userID); err != nil {
+		return err
+	}
+
+	if err := poll.CanChangeVote(time.Now()); err != nil {
+		return err
+	}
+
+	defer tc.metrics.ObserveTarantool("DeleteVote", time.Now())
+	_, err = tc.conn.Delete("votes", "primary", []interface{}{pollID, userID})
+	return err
+}
+
+func (tc *TarantoolClient) GetVoters(ctx context.Context, pollID string) ([]string, error) {
+	defer tc.metrics.ObserveTarantool("GetVoters", time.Now())
+	resp, err := tc.conn.Select("votes", "poll_idx", 0, 0, tarantool.IterEq, []interface{}{pollID})
+	if err != nil {
+		return nil, err
+	}
+
+	voters := make([]string, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		voters = append(voters, stringField(tuple, 1))
+	}
+	return voters, nil
+}
+
+func (tc *TarantoolClient) GetVotes(ctx context.Context, pollID string) ([]Vote, error) {
+	defer tc.metrics.ObserveTarantool("GetVotes", time.Now())
+	resp, err := tc.conn.Select("votes", "poll_idx", 0, 0, tarantool.IterEq, []interface{}{pollID})
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make([]Vote, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		votes = append(votes, Vote{
+			PollID:  stringField(tuple, 0),
+			UserID:  stringField(tuple, 1),
+			Option:  stringField(tuple, 2),
+			VotedAt: intField(tuple, 3),
+		})
+	}
+	return votes, nil
+}
+
+func (tc *TarantoolClient) GetResults(ctx context.Context, pollID string) (*VoteResult, error) {
+	poll, err := tc.GetPoll(ctx, pollID)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := tc.conn.Call("box.space.votes.index.poll_idx:select", []interface{}{pollID})
+	votes, err := tc.GetVotes(ctx, pollID)
 	if err != nil {
 		return nil, err
 	}
 
-	votes := make(map[string]int)
-	for _, item := range resp.Data {
-		data := item.([]interface{})
-		option := data[2].(string)
-		votes[option]++
+	// Доверенности на голосование перекрывают доверенности на весь канал.
+	// В викторине каждый отвечает сам, поэтому доверенности не учитываются.
+	var delegations []Delegation
+	if poll.Type != PollQuiz {
+		for _, scope := range []string{poll.ChannelID, poll.PollID} {
+			if scope == "" {
+				continue
+			}
+
+			scoped, err := tc.GetDelegations(ctx, scope)
+			if err != nil {
+				return nil, err
+			}
+			delegations = append(delegations, scoped...)
+		}
 	}
 
 	result := &VoteResult{
 		Question: poll.Question,
+		Status:   poll.Status,
+		Type:     poll.Type,
+		ScoreMin: poll.ScoreMin,
+		ScoreMax: poll.ScoreMax,
 		Options:  poll.Options,
+		Correct:  poll.Correct,
+		Ballots:  resolveDelegations(poll.PollID, votes, delegations),
 		Votes:    make([]int, len(poll.Options)),
 		Total:    0,
+		Quorum:   poll.Quorum,
+		Eligible: poll.Eligible,
+	}
+
+	scores := make([][]int, len(poll.Options))
+	for _, vote := range result.Ballots {
+		ballot, err := poll.DecodeBallot(vote.Option)
+		if err != nil {
+			continue
+		}
+
+		for optionNum, value := range ballot {
+			result.Votes[optionNum-1]++
+			scores[optionNum-1] = append(scores[optionNum-1], value)
+		}
+		result.Total++
+		if vote.Delegated {
+			result.Delegated++
+		}
+		if poll.Type == PollQuiz && poll.IsCorrect(vote.Option) {
+			result.CorrectVotes++
+		}
 	}
 
-	for i := range poll.Options {
-		result.Votes[i] = votes[fmt.Sprint(i+1)]
-		result.Total += result.Votes[i]
+	if poll.Type == PollScore || poll.Type == PollSchedule {
+		result.Scores = make([]ScoreStats, len(poll.Options))
+		for i := range scores {
+			result.Scores[i] = newScoreStats(scores[i])
+		}
 	}
 
+	result.Rank()
 	return result, nil
 }
 
-func (tc *TarantoolClient) UpdatePollStatus(pollID, status string) error {
+// resolveDelegations дополняет прямые голоса голосами по доверенности.
+// Цепочка доверенностей проходится до первого проголосовавшего лично;
+// прямой голос отменяет доверенность, а голоса из циклов не учитываются.
+// Более поздние записи delegations перекрывают ранние для того же участника.
+func resolveDelegations(pollID string, votes []Vote, delegations []Delegation) []Vote {
+	direct := make(map[string]Vote, len(votes))
+	for _, vote := range votes {
+		direct[vote.UserID] = vote
+	}
+
+	delegate := make(map[string]string, len(delegations))
+	var delegators []string
+	for _, d := range delegations {
+		if _, ok := delegate[d.FromUser]; !ok {
+			delegators = append(delegators, d.FromUser)
+		}
+		delegate[d.FromUser] = d.ToUser
+	}
+
+	ballots := append([]Vote(nil), votes...)
+	for _, from := range delegators {
+		if _, voted := direct[from]; voted {
+			continue
+		}
+
+		visited := map[string]bool{from: true}
+		for current := from; ; {
+			next, ok := delegate[current]
+			if !ok || visited[next] {
+				break
+			}
+
+			if vote, voted := direct[next]; voted {
+				ballots = append(ballots, Vote{PollID: pollID, UserID: from, Option: vote.Option, VotedAt: vote.VotedAt, Delegated: true})
+				break
+			}
+
+			visited[next] = true
+			current = next
+		}
+	}
+	return ballots
+}
+
+func (tc *TarantoolClient) UpdatePollStatus(ctx context.Context, pollID, status string) error {
+	defer tc.metrics.ObserveTarantool("UpdatePollStatus", time.Now())
 	_, err := tc.conn.Update("polls", "primary", []interface{}{pollID}, []interface{}{
 		[]interface{}{"=", 4, status},
 	})
 	return err
 }
 
-func (tc *TarantoolClient) DeletePoll(pollID string) error {
+// ClosePoll закрывает голосование, только если оно ещё активно. true
+// означает, что закрыли его мы и итоги должны объявить мы, а не /endpoll
+// или планировщик другой реплики, сработавшие одновременно.
+func (tc *TarantoolClient) ClosePoll(ctx context.Context, pollID string) (bool, error) {
+	defer tc.metrics.ObserveTarantool("ClosePoll", time.Now())
+	resp, err := tc.conn.Call17("close_poll", []interface{}{pollID})
+	if err != nil {
+		return false, err
+	}
+
+	if len(resp.Data) == 0 {
+		return false, nil
+	}
+	closed, _ := resp.Data[0].(bool)
+	return closed, nil
+}
+
+func (tc *TarantoolClient) DeletePoll(ctx context.Context, pollID string) error {
+	defer tc.metrics.ObserveTarantool("DeletePoll", time.Now())
 	if _, err := tc.conn.Delete("polls", "primary", []interface{}{pollID}); err != nil {
 		return err
 	}
 
-	resp, err := tc.conn.Select("votes", "poll_idx", 0, 0, tarantool.IterEq, []interface{}{pollID})
+	// Голоса, ответы, напоминания, веса, доверенности и итоги викторины удаляются по первичному ключу
+	for _, space := range []string{"votes", "answers", "reminders", "weights", "delegations", "quiz_results"} {
+		if err := tc.deletePollTuples(space, pollID); err != nil {
+			return err
+		}
+	}
+
+	_, err := tc.conn.Delete("final_results", "primary", []interface{}{pollID})
+	return err
+}
+
+// deletePollTuples удаляет из space все кортежи с первичным ключом (poll_id, ...).
+func (tc *TarantoolClient) deletePollTuples(space, pollID string) error {
+	resp, err := tc.conn.Select(space, "primary", 0, 0, tarantool.IterEq, []interface{}{pollID})
 	if err != nil {
 		return err
 	}
 
-	// Удалить каждый голос по первичному ключу
 	for _, tuple := range resp.Tuples() {
-		_, err := tc.conn.Delete("votes", "primary", []interface{}{tuple[0], tuple[1]}) // poll_id, user_id
-		if err != nil {
+		if _, err := tc.conn.Delete(space, "primary", []interface{}{tuple[0], tuple[1]}); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+func (tc *TarantoolClient) AddReminder(ctx context.Context, pollID string, remindAt int64) error {
+	defer tc.metrics.ObserveTarantool("AddReminder", time.Now())
+	_, err := tc.conn.Replace("reminders", []interface{}{pollID, remindAt})
 	return err
 }
 
+// GetDueReminders возвращает напоминания, время которых наступило к моменту now.
+func (tc *TarantoolClient) GetDueReminders(ctx context.Context, now int64) ([]Reminder, error) {
+	defer tc.metrics.ObserveTarantool("GetDueReminders", time.Now())
+	resp, err := tc.conn.Select("reminders", "remind_at_idx", 0, 0, tarantool.IterLe, []interface{}{now})
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]Reminder, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		reminders = append(reminders, Reminder{
+			PollID:   stringField(tuple, 0),
+			RemindAt: intField(tuple, 1),
+		})
+	}
+	return reminders, nil
+}
+
+// ClaimReminder удаляет напоминание и сообщает, было ли оно ещё на месте.
+// Удаление атомарно, поэтому при нескольких репликах бота напоминание
+// рассылает только та, что удалила его первой.
+func (tc *TarantoolClient) ClaimReminder(ctx context.Context, pollID string, remindAt int64) (bool, error) {
+	defer tc.metrics.ObserveTarantool("ClaimReminder", time.Now())
+	resp, err := tc.conn.Delete("reminders", "primary", []interface{}{pollID, remindAt})
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Data) > 0, nil
+}
+
+// GetExpiredPolls возвращает активные голосования, срок которых истёк к моменту now.
+func (tc *TarantoolClient) GetExpiredPolls(ctx context.Context, now int64) ([]*Poll, error) {
+	defer tc.metrics.ObserveTarantool("GetExpiredPolls", time.Now())
+	selected, err := tc.selectPolls("status_deadline_idx", 0, tarantool.IterLe, []interface{}{"active", now})
+	if err != nil {
+		return nil, err
+	}
+
+	var polls []*Poll
+	for i := range selected {
+		poll := &selected[i]
+		if poll.Status != "active" {
+			break
+		}
+		if poll.Deadline > 0 {
+			polls = append(polls, poll)
+		}
+	}
+	return polls, nil
+}
+
+func (tc *TarantoolClient) SetWeight(ctx context.Context, weight Weight) error {
+	defer tc.metrics.ObserveTarantool("SetWeight", time.Now())
+	_, err := tc.conn.Replace("weights", []interface{}{
+		weight.Scope,
+		weight.Subject,
+		weight.Value,
+		weight.Name,
+	})
+	return err
+}
+
+func (tc *TarantoolClient) DeleteWeight(ctx context.Context, scope, subject string) error {
+	defer tc.metrics.ObserveTarantool("DeleteWeight", time.Now())
+	resp, err := tc.conn.Delete("weights", "primary", []interface{}{scope, subject})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Data) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (tc *TarantoolClient) GetWeights(ctx context.Context, scope string) ([]Weight, error) {
+	defer tc.metrics.ObserveTarantool("GetWeights", time.Now())
+	resp, err := tc.conn.Select("weights", "primary", 0, 0, tarantool.IterEq, []interface{}{scope})
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make([]Weight, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		weights = append(weights, Weight{
+			Scope:   stringField(tuple, 0),
+			Subject: stringField(tuple, 1),
+			Value:   floatField(tuple, 2),
+			Name:    stringField(tuple, 3),
+		})
+	}
+	return weights, nil
+}
+
+func (tc *TarantoolClient) SetDelegation(ctx context.Context, delegation Delegation) error {
+	defer tc.metrics.ObserveTarantool("SetDelegation", time.Now())
+	_, err := tc.conn.Replace("delegations", []interface{}{
+		delegation.Scope,
+		delegation.FromUser,
+		delegation.ToUser,
+	})
+	return err
+}
+
+func (tc *TarantoolClient) DeleteDelegation(ctx context.Context, scope, fromUser string) error {
+	defer tc.metrics.ObserveTarantool("DeleteDelegation", time.Now())
+	resp, err := tc.conn.Delete("delegations", "primary", []interface{}{scope, fromUser})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Data) == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (tc *TarantoolClient) GetDelegations(ctx context.Context, scope string) ([]Delegation, error) {
+	defer tc.metrics.ObserveTarantool("GetDelegations", time.Now())
+	resp, err := tc.conn.Select("delegations", "primary", 0, 0, tarantool.IterEq, []interface{}{scope})
+	if err != nil {
+		return nil, err
+	}
+
+	delegations := make([]Delegation, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		delegations = append(delegations, Delegation{
+			Scope:    stringField(tuple, 0),
+			FromUser: stringField(tuple, 1),
+			ToUser:   stringField(tuple, 2),
+		})
+	}
+	return delegations, nil
+}
+
+// AddAnswer сохраняет ответ участника; повторный ответ заменяет предыдущий.
+func (tc *TarantoolClient) AddAnswer(ctx context.Context, answer Answer) error {
+	defer tc.metrics.ObserveTarantool("AddAnswer", time.Now())
+	_, err := tc.conn.Replace("answers", []interface{}{
+		answer.PollID,
+		answer.UserID,
+		answer.Text,
+		answer.CreatedAt,
+	})
+	return err
+}
+
+// GetAnswers возвращает ответы голосования в порядке их поступления.
+func (tc *TarantoolClient) GetAnswers(ctx context.Context, pollID string) ([]Answer, error) {
+	defer tc.metrics.ObserveTarantool("GetAnswers", time.Now())
+	resp, err := tc.conn.Select("answers", "primary", 0, 0, tarantool.IterEq, []interface{}{pollID})
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]Answer, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		answers = append(answers, Answer{
+			PollID:    stringField(tuple, 0),
+			UserID:    stringField(tuple, 1),
+			Text:      stringField(tuple, 2),
+			CreatedAt: intField(tuple, 3),
+		})
+	}
+
+	sort.SliceStable(answers, func(i, j int) bool {
+		return answers[i].CreatedAt < answers[j].CreatedAt
+	})
+	return answers, nil
+}
+
+func (tc *TarantoolClient) SaveTemplate(ctx context.Context, template Template) error {
+	defer tc.metrics.ObserveTarantool("SaveTemplate", time.Now())
+	_, err := tc.conn.Replace("templates", []interface{}{
+		template.Name,
+		template.CreatorID,
+		pollTuple(template.Poll),
+	})
+	return err
+}
+
+func (tc *TarantoolClient) GetTemplate(ctx context.Context, name string) (*Template, error) {
+	defer tc.metrics.ObserveTarantool("GetTemplate", time.Now())
+	templates, err := tc.selectTemplates(1, tarantool.IterEq, []interface{}{name})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(templates) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &templates[0], nil
+}
+
+func (tc *TarantoolClient) GetTemplates(ctx context.Context) ([]Template, error) {
+	defer tc.metrics.ObserveTarantool("GetTemplates", time.Now())
+	return tc.selectTemplates(0, tarantool.IterAll, []interface{}{})
+}
+
+func (tc *TarantoolClient) selectTemplates(limit, iterator uint32, key []interface{}) ([]Template, error) {
+	var templates []Template
+	if err := tc.conn.SelectTyped("templates", "primary", 0, limit, iterator, key, &templates); err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		if template.Poll == nil {
+			return nil, fmt.Errorf("template %q has no poll", template.Name)
+		}
+		template.Poll.setDefaults()
+	}
+	return templates, nil
+}
+
+func (tc *TarantoolClient) SaveQuizResult(ctx context.Context, result QuizResult) error {
+	defer tc.metrics.ObserveTarantool("SaveQuizResult", time.Now())
+	_, err := tc.conn.Replace("quiz_results", []interface{}{
+		result.PollID,
+		result.UserID,
+		result.ChannelID,
+		result.Correct,
+	})
+	return err
+}
+
+// GetQuizResults возвращает итоги всех викторин канала.
+func (tc *TarantoolClient) GetQuizResults(ctx context.Context, channelID string) ([]QuizResult, error) {
+	defer tc.metrics.ObserveTarantool("GetQuizResults", time.Now())
+	resp, err := tc.conn.Select("quiz_results", "channel_idx", 0, 0, tarantool.IterEq, []interface{}{channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]QuizResult, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		correct, _ := tuple[3].(bool)
+		results = append(results, QuizResult{
+			PollID:    stringField(tuple, 0),
+			UserID:    stringField(tuple, 1),
+			ChannelID: stringField(tuple, 2),
+			Correct:   correct,
+		})
+	}
+	return results, nil
+}
+
+// SaveFinalResult фиксирует опубликованные итоги. Повторная запись для того
+// же голосования возвращает ошибку, а не заменяет итоги.
+func (tc *TarantoolClient) SaveFinalResult(ctx context.Context, result FinalResult) error {
+	defer tc.metrics.ObserveTarantool("SaveFinalResult", time.Now())
+	_, err := tc.conn.Insert("final_results", []interface{}{
+		result.PollID,
+		result.Summary,
+		result.PostID,
+		result.ClosedAt,
+	})
+	return err
+}
+
+func (tc *TarantoolClient) GetFinalResult(ctx context.Context, pollID string) (*FinalResult, error) {
+	defer tc.metrics.ObserveTarantool("GetFinalResult", time.Now())
+	resp, err := tc.conn.Select("final_results", "primary", 0, 1, tarantool.IterEq, []interface{}{pollID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, ErrNotFound
+	}
+
+	tuple := resp.Tuples()[0]
+	return &FinalResult{
+		PollID:   stringField(tuple, 0),
+		Summary:  stringField(tuple, 1),
+		PostID:   stringField(tuple, 2),
+		ClosedAt: intField(tuple, 3),
+	}, nil
+}
+
+func (tc *TarantoolClient) AddRecurrence(ctx context.Context, recurrence Recurrence) error {
+	defer tc.metrics.ObserveTarantool("AddRecurrence", time.Now())
+	_, err := tc.conn.Insert("recurrences", []interface{}{
+		recurrence.ID,
+		recurrence.ChannelID,
+		recurrence.CreatorID,
+		recurrence.Cron,
+		recurrence.Timezone,
+		recurrence.Template,
+		recurrence.NextRun,
+	})
+	return err
+}
+
+func (tc *TarantoolClient) GetRecurrence(ctx context.Context, id string) (*Recurrence, error) {
+	defer tc.metrics.ObserveTarantool("GetRecurrence", time.Now())
+	resp, err := tc.conn.Select("recurrences", "primary", 0, 1, tarantool.IterEq, []interface{}{id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, ErrNotFound
+	}
+
+	recurrence := recurrenceFromTuple(resp.Tuples()[0])
+	return &recurrence, nil
+}
+
+// GetRecurrences возвращает регулярные голосования канала.
+func (tc *TarantoolClient) GetRecurrences(ctx context.Context, channelID string) ([]Recurrence, error) {
+	defer tc.metrics.ObserveTarantool("GetRecurrences", time.Now())
+	return tc.selectRecurrences("channel_idx", tarantool.IterEq, channelID)
+}
+
+// GetDueRecurrences возвращает регулярные голосования, запуск которых наступил к моменту now.
+func (tc *TarantoolClient) GetDueRecurrences(ctx context.Context, now int64) ([]Recurrence, error) {
+	defer tc.metrics.ObserveTarantool("GetDueRecurrences", time.Now())
+	return tc.selectRecurrences("next_run_idx", tarantool.IterLe, now)
+}
+
+func (tc *TarantoolClient) selectRecurrences(index string, iterator uint32, key interface{}) ([]Recurrence, error) {
+	resp, err := tc.conn.Select("recurrences", index, 0, 0, iterator, []interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+
+	recurrences := make([]Recurrence, 0, len(resp.Data))
+	for _, tuple := range resp.Tuples() {
+		recurrences = append(recurrences, recurrenceFromTuple(tuple))
+	}
+	return recurrences, nil
+}
+
+// ClaimRecurrence переносит запуск с expected на next, только если его ещё
+// не перенесла другая реплика бота. true означает, что запуск достался нам.
+func (tc *TarantoolClient) ClaimRecurrence(ctx context.Context, id string, expected, next int64) (bool, error) {
+	defer tc.metrics.ObserveTarantool("ClaimRecurrence", time.Now())
+	resp, err := tc.conn.Call17("claim_recurrence", []interface{}{id, expected, next})
+	if err != nil {
+		return false, err
+	}
+
+	if len(resp.Data) == 0 {
+		return false, nil
+	}
+	claimed, _ := resp.Data[0].(bool)
+	return claimed, nil
+}
+
+func (tc *TarantoolClient) DeleteRecurrence(ctx context.Context, id string) error {
+	defer tc.metrics.ObserveTarantool("DeleteRecurrence", time.Now())
+	_, err := tc.conn.Delete("recurrences", "primary", []interface{}{id})
+	return err
+}
+
+// ClaimPost отмечает пост с командой обработанным и сдвигает позицию чтения
+// канала до createdAt (миллисекунды, как CreateAt в Mattermost). Возвращает
+// false, если пост уже был обработан.
+func (tc *TarantoolClient) ClaimPost(ctx context.Context, postID, channelID string, createdAt int64) (bool, error) {
+	defer tc.metrics.ObserveTarantool("ClaimPost", time.Now())
+	resp, err := tc.conn.Call17("claim_post", []interface{}{postID, channelID, createdAt})
+	if err != nil {
+		return false, err
+	}
+
+	if len(resp.Data) == 0 {
+		return false, nil
+	}
+	claimed, _ := resp.Data[0].(bool)
+	return claimed, nil
+}
+
+// GetChannelCursor возвращает время последнего обработанного поста канала,
+// 0 — канал ещё не читался.
+func (tc *TarantoolClient) GetChannelCursor(ctx context.Context, channelID string) (int64, error) {
+	defer tc.metrics.ObserveTarantool("GetChannelCursor", time.Now())
+	resp, err := tc.conn.Select("channel_cursors", "primary", 0, 1, tarantool.IterEq, []interface{}{channelID})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp.Data) == 0 {
+		return 0, nil
+	}
+	return intField(resp.Tuples()[0], 1), nil
+}
+
+// AdvanceChannelCursor сдвигает позицию чтения канала вперёд; более раннее
+// время игнорируется.
+func (tc *TarantoolClient) AdvanceChannelCursor(ctx context.Context, channelID string, lastPostAt int64) error {
+	defer tc.metrics.ObserveTarantool("AdvanceChannelCursor", time.Now())
+	_, err := tc.conn.Call17("advance_cursor", []interface{}{channelID, lastPostAt})
+	return err
+}
+
+func recurrenceFromTuple(tuple []interface{}) Recurrence {
+	return Recurrence{
+		ID:        stringField(tuple, 0),
+		ChannelID: stringField(tuple, 1),
+		CreatorID: stringField(tuple, 2),
+		Cron:      stringField(tuple, 3),
+		Timezone:  stringField(tuple, 4),
+		Template:  stringField(tuple, 5),
+		NextRun:   intField(tuple, 6),
+	}
+}
+
 func (tc *TarantoolClient) Close() error {
 	return tc.conn.Close()
 }
 
-func convertToStringSlice(in []interface{}) []string {
-	out := make([]string, len(in))
-	for i, v := range in {
-		out[i] = v.(string)
+// setDefaults заполняет поля, которых нет в кортежах старых голосований.
+func (p *Poll) setDefaults() {
+	if p.Type == "" {
+		p.Type = PollSingle
+	}
+}
+
+// stringField и intField читают поля кортежа, не падая на полях, которых нет
+// в кортежах, созданных до расширения формата, и на значениях другого типа.
+func stringField(data []interface{}, i int) string {
+	if i >= len(data) {
+		return ""
+	}
+	s, _ := data[i].(string)
+	return s
+}
+
+func intField(data []interface{}, i int) int64 {
+	if i >= len(data) {
+		return 0
+	}
+	switch v := data[i].(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	}
+	return 0
+}
+
+func floatField(data []interface{}, i int) float64 {
+	if i >= len(data) {
+		return 0
+	}
+	switch v := data[i].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
 	}
-	return out
+	return float64(intField(data, i))
 }
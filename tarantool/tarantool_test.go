@@ -1,6 +1,7 @@
 package tarantool
 
 import (
+	"context"
 	"log"
 	"testing"
 	"time"
@@ -17,6 +18,8 @@ func TestTarantoolClient(t *testing.T) {
 	require.NoError(t, err, "Failed to connect to Tarantool")
 	defer client.Close()
 
+	ctx := context.Background()
+
 	// Генерация уникальных данных для теста
 	pollID := "test_poll_" + uuid.New().String()
 	userID := "test_user_" + uuid.New().String()
@@ -24,10 +27,23 @@ func TestTarantoolClient(t *testing.T) {
 	options := []string{"Option1", "Option2"}
 
 	t.Run("Create and Get Poll", func(t *testing.T) {
-		err := client.CreatePoll(pollID, userID, question, options)
+		err := client.CreatePoll(ctx, &Poll{
+			PollID:    pollID,
+			CreatorID: userID,
+			Question:  question,
+			Options:   options,
+			ChannelID: "test_channel",
+			CreatedAt: time.Now().Unix(),
+			Deadline:  time.Now().Add(time.Hour).Unix(),
+			Quorum:    2,
+			Eligible:  3,
+		})
+		assert.NoError(t, err)
+
+		err = client.SetPollPost(ctx, pollID, "test_post")
 		assert.NoError(t, err)
 
-		poll, err := client.GetPoll(pollID)
+		poll, err := client.GetPoll(ctx, pollID)
 		require.NoError(t, err)
 		require.NotNil(t, poll)
 
@@ -36,19 +52,264 @@ func TestTarantoolClient(t *testing.T) {
 		assert.Equal(t, question, poll.Question)
 		assert.Equal(t, options, poll.Options)
 		assert.Equal(t, "active", poll.Status)
+		assert.Equal(t, "test_channel", poll.ChannelID)
+		assert.Equal(t, "test_post", poll.PostID)
+		assert.NotZero(t, poll.Deadline)
+		assert.Equal(t, 2, poll.Quorum)
+		assert.Equal(t, 3, poll.Eligible)
+		assert.Equal(t, PollSingle, poll.Type)
+
+		byPost, err := client.GetPollByPost(ctx, "test_post")
+		require.NoError(t, err)
+		assert.Equal(t, pollID, byPost.PollID)
+	})
+
+	t.Run("Score Poll", func(t *testing.T) {
+		scorePollID := "test_score_poll_" + uuid.New().String()
+		err := client.CreatePoll(ctx, &Poll{
+			PollID:    scorePollID,
+			CreatorID: userID,
+			Question:  question,
+			Options:   options,
+			Type:      PollScore,
+			ScoreMin:  0,
+			ScoreMax:  5,
+		})
+		require.NoError(t, err)
+		defer client.DeletePoll(ctx, scorePollID)
+
+		assert.NoError(t, client.AddVote(ctx, scorePollID, "user1", "1=5,2=1"))
+		assert.NoError(t, client.AddVote(ctx, scorePollID, "user2", "1=4"))
+		assert.ErrorIs(t, client.AddVote(ctx, scorePollID, "user3", "1=6"), ErrInvalidOption)
+
+		results, err := client.GetResults(ctx, scorePollID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, results.Total)
+		assert.Equal(t, []int{2, 1}, results.Votes)
+		assert.Equal(t, 4.5, results.Scores[0].Average)
+		assert.Equal(t, 1.0, results.Scores[1].Median)
+	})
+
+	t.Run("Text Poll", func(t *testing.T) {
+		textPollID := "test_text_poll_" + uuid.New().String()
+		err := client.CreatePoll(ctx, &Poll{
+			PollID:    textPollID,
+			CreatorID: userID,
+			Question:  question,
+			Type:      PollText,
+			Anonymous: true,
+		})
+		require.NoError(t, err)
+		defer client.DeletePoll(ctx, textPollID)
+
+		poll, err := client.GetPoll(ctx, textPollID)
+		require.NoError(t, err)
+		assert.Equal(t, PollText, poll.Type)
+		assert.True(t, poll.Anonymous)
+
+		assert.NoError(t, client.AddAnswer(ctx, Answer{PollID: textPollID, UserID: "user2", Text: "второй", CreatedAt: 2}))
+		assert.NoError(t, client.AddAnswer(ctx, Answer{PollID: textPollID, UserID: "user1", Text: "первый", CreatedAt: 1}))
+
+		answers, err := client.GetAnswers(ctx, textPollID)
+		require.NoError(t, err)
+		require.Len(t, answers, 2)
+		assert.Equal(t, "первый", answers[0].Text)
+		assert.Equal(t, "user2", answers[1].UserID)
+	})
+
+	t.Run("Quiz", func(t *testing.T) {
+		quizPollID := "test_quiz_poll_" + uuid.New().String()
+		err := client.CreatePoll(ctx, &Poll{
+			PollID:    quizPollID,
+			CreatorID: userID,
+			Question:  question,
+			Options:   options,
+			ChannelID: "quiz-channel",
+			Type:      PollQuiz,
+			Correct:   []int{2},
+		})
+		require.NoError(t, err)
+		defer client.DeletePoll(ctx, quizPollID)
+
+		poll, err := client.GetPoll(ctx, quizPollID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2}, poll.Correct)
+
+		assert.NoError(t, client.AddVote(ctx, quizPollID, "user1", "2"))
+		assert.NoError(t, client.AddVote(ctx, quizPollID, "user2", "1"))
+
+		// Доверенность в викторине не добавляет голосов
+		delegation := Delegation{Scope: quizPollID, FromUser: "user3", ToUser: "user1"}
+		require.NoError(t, client.SetDelegation(ctx, delegation))
+		defer client.DeleteDelegation(ctx, delegation.Scope, delegation.FromUser)
+
+		results, err := client.GetResults(ctx, quizPollID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, results.CorrectVotes)
+		assert.Equal(t, 2, results.Total)
+		assert.Zero(t, results.Delegated)
+
+		assert.NoError(t, client.SaveQuizResult(ctx, QuizResult{PollID: quizPollID, UserID: "user1", ChannelID: "quiz-channel", Correct: true}))
+		quizResults, err := client.GetQuizResults(ctx, "quiz-channel")
+		require.NoError(t, err)
+		assert.Equal(t, []QuizResult{{PollID: quizPollID, UserID: "user1", ChannelID: "quiz-channel", Correct: true}}, quizResults)
+	})
+
+	t.Run("Vote Change Policy", func(t *testing.T) {
+		lockedPollID := "test_locked_poll_" + uuid.New().String()
+		err := client.CreatePoll(ctx, &Poll{
+			PollID:     lockedPollID,
+			CreatorID:  userID,
+			Question:   question,
+			Options:    options,
+			VoteChange: VoteChangeLocked,
+		})
+		require.NoError(t, err)
+		defer client.DeletePoll(ctx, lockedPollID)
+
+		poll, err := client.GetPoll(ctx, lockedPollID)
+		require.NoError(t, err)
+		assert.Equal(t, VoteChangeLocked, poll.VoteChange)
+
+		assert.NoError(t, client.AddVote(ctx, lockedPollID, "user1", "1"))
+		assert.NoError(t, client.AddVote(ctx, lockedPollID, "user1", "1"))
+		assert.ErrorIs(t, client.AddVote(ctx, lockedPollID, "user1", "2"), ErrVoteLocked)
+		assert.ErrorIs(t, client.DeleteVote(ctx, lockedPollID, "user1"), ErrVoteLocked)
+		assert.ErrorIs(t, client.DeleteVote(ctx, lockedPollID, "user2"), ErrNotFound)
+
+		require.NoError(t, client.UpdatePollStatus(ctx, lockedPollID, "closed"))
+		assert.ErrorIs(t, client.AddVote(ctx, lockedPollID, "user2", "1"), ErrPollClosed)
+	})
+
+	t.Run("Templates", func(t *testing.T) {
+		poll, err := client.GetPoll(ctx, pollID)
+		require.NoError(t, err)
+
+		template := Template{Name: "test_template_" + uuid.New().String(), CreatorID: userID, Poll: poll}
+		require.NoError(t, client.SaveTemplate(ctx, template))
+
+		saved, err := client.GetTemplate(ctx, template.Name)
+		require.NoError(t, err)
+		assert.Equal(t, template, *saved)
+
+		templates, err := client.GetTemplates(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, templates, template)
+
+		_, err = client.GetTemplate(ctx, "missing_template")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Recurrences", func(t *testing.T) {
+		recurrence := Recurrence{
+			ID:        "test_recurrence_" + uuid.New().String(),
+			ChannelID: "test_channel",
+			CreatorID: userID,
+			Cron:      "0 10 * * MON",
+			Timezone:  "Europe/Moscow",
+			Template:  "standup",
+			NextRun:   1000,
+		}
+		require.NoError(t, client.AddRecurrence(ctx, recurrence))
+
+		due, err := client.GetDueRecurrences(ctx, 1000)
+		require.NoError(t, err)
+		assert.Contains(t, due, recurrence)
+
+		// Вторая реплика с устаревшим next_run запуск не получает
+		claimed, err := client.ClaimRecurrence(ctx, recurrence.ID, 1000, 2000)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = client.ClaimRecurrence(ctx, recurrence.ID, 1000, 2000)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+
+		saved, err := client.GetRecurrence(ctx, recurrence.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2000), saved.NextRun)
+
+		channel, err := client.GetRecurrences(ctx, "test_channel")
+		require.NoError(t, err)
+		assert.Len(t, channel, 1)
+
+		require.NoError(t, client.DeleteRecurrence(ctx, recurrence.ID))
+		_, err = client.GetRecurrence(ctx, recurrence.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Final Results", func(t *testing.T) {
+		result := FinalResult{
+			PollID:   "test_final_" + uuid.New().String(),
+			Summary:  "Победитель: A",
+			PostID:   "results_post",
+			ClosedAt: time.Now().Unix(),
+		}
+		require.NoError(t, client.SaveFinalResult(ctx, result))
+
+		// Опубликованные итоги не перезаписываются
+		assert.Error(t, client.SaveFinalResult(ctx, FinalResult{PollID: result.PollID, Summary: "Ничья"}))
+
+		saved, err := client.GetFinalResult(ctx, result.PollID)
+		require.NoError(t, err)
+		assert.Equal(t, result, *saved)
+
+		_, err = client.GetFinalResult(ctx, "missing_poll")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Processed Posts", func(t *testing.T) {
+		postID := "test_post_" + uuid.New().String()
+
+		cursor, err := client.GetChannelCursor(ctx, "catchup_channel")
+		require.NoError(t, err)
+		assert.Zero(t, cursor)
+
+		// Команда выполняется один раз
+		claimed, err := client.ClaimPost(ctx, postID, "catchup_channel", 2000)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = client.ClaimPost(ctx, postID, "catchup_channel", 2000)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+
+		// Позиция чтения не сдвигается назад
+		require.NoError(t, client.AdvanceChannelCursor(ctx, "catchup_channel", 1000))
+		cursor, err = client.GetChannelCursor(ctx, "catchup_channel")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2000), cursor)
+
+		require.NoError(t, client.AdvanceChannelCursor(ctx, "catchup_channel", 3000))
+		cursor, err = client.GetChannelCursor(ctx, "catchup_channel")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3000), cursor)
+	})
+
+	t.Run("Readiness", func(t *testing.T) {
+		require.NoError(t, client.Ping(ctx))
+
+		version, err := client.GetSchemaVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(SchemaVersion), version)
+
+		// Отменённый контекст прерывает проверку, не дожидаясь ответа
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Error(t, client.Ping(cancelled))
+		_, err = client.GetSchemaVersion(cancelled)
+		assert.Error(t, err)
 	})
 
 	t.Run("Vote Handling", func(t *testing.T) {
 		// Голосование первого пользователя
-		err := client.AddVote(pollID, "user1", "1")
+		err := client.AddVote(ctx, pollID, "user1", "1")
 		assert.NoError(t, err)
 
 		// Голосование второго пользователя
-		err = client.AddVote(pollID, "user2", "2")
+		err = client.AddVote(ctx, pollID, "user2", "2")
 		assert.NoError(t, err)
 
 		// Проверка результатов
-		results, err := client.GetResults(pollID)
+		results, err := client.GetResults(ctx, pollID)
 		require.NoError(t, err)
 		require.NotNil(t, results)
 
@@ -56,40 +317,216 @@ func TestTarantoolClient(t *testing.T) {
 		assert.Equal(t, options, results.Options)
 		assert.Equal(t, []int{1, 1}, results.Votes)
 		assert.Equal(t, 2, results.Total)
+		assert.True(t, results.QuorumReached())
+
+		voters, err := client.GetVoters(ctx, pollID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user1", "user2"}, voters)
+
+		votes, err := client.GetVotes(ctx, pollID)
+		require.NoError(t, err)
+		for i := range votes {
+			assert.NotZero(t, votes[i].VotedAt)
+			votes[i].VotedAt = 0
+		}
+		assert.ElementsMatch(t, []Vote{
+			{PollID: pollID, UserID: "user1", Option: "1"},
+			{PollID: pollID, UserID: "user2", Option: "2"},
+		}, votes)
+	})
+
+	t.Run("Single Vote", func(t *testing.T) {
+		vote, err := client.GetVote(ctx, pollID, "user2")
+		require.NoError(t, err)
+		assert.Equal(t, "2", vote.Option)
+
+		err = client.AddVote(ctx, pollID, "user4", "1")
+		require.NoError(t, err)
+		err = client.DeleteVote(ctx, pollID, "user4")
+		assert.NoError(t, err)
+
+		_, err = client.GetVote(ctx, pollID, "user4")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Weights", func(t *testing.T) {
+		weight := Weight{Scope: pollID, Subject: "user:user1", Value: 2.5, Name: "@user1"}
+		err := client.SetWeight(ctx, weight)
+		assert.NoError(t, err)
+
+		weights, err := client.GetWeights(ctx, pollID)
+		require.NoError(t, err)
+		assert.Equal(t, []Weight{weight}, weights)
+
+		err = client.DeleteWeight(ctx, pollID, "user:user1")
+		assert.NoError(t, err)
+
+		err = client.DeleteWeight(ctx, pollID, "user:user1")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Reminders", func(t *testing.T) {
+		remindAt := time.Now().Unix()
+		err := client.AddReminder(ctx, pollID, remindAt)
+		assert.NoError(t, err)
+
+		reminders, err := client.GetDueReminders(ctx, remindAt-1)
+		require.NoError(t, err)
+		assert.NotContains(t, reminders, Reminder{PollID: pollID, RemindAt: remindAt})
+
+		reminders, err = client.GetDueReminders(ctx, remindAt)
+		require.NoError(t, err)
+		assert.Contains(t, reminders, Reminder{PollID: pollID, RemindAt: remindAt})
+
+		// Напоминание достаётся только одной реплике
+		claimed, err := client.ClaimReminder(ctx, pollID, remindAt)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = client.ClaimReminder(ctx, pollID, remindAt)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+
+		reminders, err = client.GetDueReminders(ctx, remindAt)
+		require.NoError(t, err)
+		assert.NotContains(t, reminders, Reminder{PollID: pollID, RemindAt: remindAt})
+	})
+
+	t.Run("Delegations", func(t *testing.T) {
+		delegation := Delegation{Scope: pollID, FromUser: "user3", ToUser: "user1"}
+		err := client.SetDelegation(ctx, delegation)
+		assert.NoError(t, err)
+
+		delegations, err := client.GetDelegations(ctx, pollID)
+		require.NoError(t, err)
+		assert.Equal(t, []Delegation{delegation}, delegations)
+
+		results, err := client.GetResults(ctx, pollID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 1}, results.Votes)
+		assert.Equal(t, 3, results.Total)
+		assert.Equal(t, 1, results.Delegated)
+
+		err = client.DeleteDelegation(ctx, pollID, "user3")
+		assert.NoError(t, err)
+
+		err = client.DeleteDelegation(ctx, pollID, "user3")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Expired Polls", func(t *testing.T) {
+		polls, err := client.GetExpiredPolls(ctx, time.Now().Unix())
+		require.NoError(t, err)
+		for _, poll := range polls {
+			assert.NotEqual(t, pollID, poll.PollID)
+		}
+
+		polls, err = client.GetExpiredPolls(ctx, time.Now().Add(2*time.Hour).Unix())
+		require.NoError(t, err)
+		var ids []string
+		for _, poll := range polls {
+			ids = append(ids, poll.PollID)
+		}
+		assert.Contains(t, ids, pollID)
 	})
 
 	t.Run("Update Poll Status", func(t *testing.T) {
-		err := client.UpdatePollStatus(pollID, "closed")
+		err := client.UpdatePollStatus(ctx, pollID, "closed")
 		assert.NoError(t, err)
 
-		poll, err := client.GetPoll(pollID)
+		poll, err := client.GetPoll(ctx, pollID)
 		require.NoError(t, err)
 		require.NotNil(t, poll)
 
 		assert.Equal(t, "closed", poll.Status)
 	})
 
+	t.Run("Close Poll", func(t *testing.T) {
+		closePollID := "test_close_poll_" + uuid.New().String()
+		require.NoError(t, client.CreatePoll(ctx, &Poll{PollID: closePollID, CreatorID: userID, Question: question, Options: options}))
+		defer client.DeletePoll(ctx, closePollID)
+
+		closed, err := client.ClosePoll(ctx, closePollID)
+		require.NoError(t, err)
+		assert.True(t, closed)
+
+		// Повторное закрытие не должно снова объявлять итоги
+		closed, err = client.ClosePoll(ctx, closePollID)
+		require.NoError(t, err)
+		assert.False(t, closed)
+
+		poll, err := client.GetPoll(ctx, closePollID)
+		require.NoError(t, err)
+		assert.Equal(t, "closed", poll.Status)
+	})
+
 	t.Run("Delete Poll", func(t *testing.T) {
-		err := client.DeletePoll(pollID)
+		err := client.DeletePoll(ctx, pollID)
 		assert.NoError(t, err)
 
-		_, err = client.GetPoll(pollID)
+		_, err = client.GetPoll(ctx, pollID)
 		assert.Error(t, err)
 	})
 
 	t.Run("Negative Cases", func(t *testing.T) {
 		t.Run("Non-existent Poll", func(t *testing.T) {
-			_, err := client.GetPoll("non_existent_poll")
+			_, err := client.GetPoll(ctx, "non_existent_poll")
 			assert.Error(t, err)
 		})
 
 		t.Run("Invalid Option", func(t *testing.T) {
-			err := client.AddVote(pollID, "user3", "3")
+			err := client.AddVote(ctx, pollID, "user3", "3")
 			assert.Error(t, err)
 		})
 	})
 }
 
+func TestCanChangeVote(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, (&Poll{}).CanChangeVote(now))
+	assert.ErrorIs(t, (&Poll{VoteChange: VoteChangeLocked}).CanChangeVote(now), ErrVoteLocked)
+
+	until := &Poll{
+		VoteChange:   VoteChangeUntil,
+		Deadline:     now.Add(time.Hour).Unix(),
+		ChangeCutoff: int64((30 * time.Minute).Seconds()),
+	}
+	assert.NoError(t, until.CanChangeVote(now))
+	assert.ErrorIs(t, until.CanChangeVote(now.Add(45*time.Minute)), ErrChangeClosed)
+}
+
+func TestResolveDelegations(t *testing.T) {
+	votes := []Vote{
+		{PollID: "poll", UserID: "alice", Option: "1", VotedAt: 100},
+		{PollID: "poll", UserID: "bob", Option: "2", VotedAt: 200},
+	}
+
+	delegations := []Delegation{
+		// Цепочка carol -> dave -> alice
+		{Scope: "channel", FromUser: "carol", ToUser: "dave"},
+		{Scope: "channel", FromUser: "dave", ToUser: "alice"},
+		// Прямой голос bob отменяет его доверенность
+		{Scope: "channel", FromUser: "bob", ToUser: "alice"},
+		// Цикл eve <-> frank не учитывается
+		{Scope: "channel", FromUser: "eve", ToUser: "frank"},
+		{Scope: "channel", FromUser: "frank", ToUser: "eve"},
+		// Доверенность на голосование перекрывает доверенность на канал
+		{Scope: "channel", FromUser: "grace", ToUser: "alice"},
+		{Scope: "poll", FromUser: "grace", ToUser: "bob"},
+		// Доверенное лицо не голосовало
+		{Scope: "poll", FromUser: "heidi", ToUser: "ivan"},
+	}
+
+	ballots := resolveDelegations("poll", votes, delegations)
+	assert.ElementsMatch(t, []Vote{
+		{PollID: "poll", UserID: "alice", Option: "1", VotedAt: 100},
+		{PollID: "poll", UserID: "bob", Option: "2", VotedAt: 200},
+		{PollID: "poll", UserID: "carol", Option: "1", VotedAt: 100, Delegated: true},
+		{PollID: "poll", UserID: "dave", Option: "1", VotedAt: 100, Delegated: true},
+		{PollID: "poll", UserID: "grace", Option: "2", VotedAt: 200, Delegated: true},
+	}, ballots)
+}
+
 func TestMain(m *testing.M) {
 	// Очистка тестовых данных перед запуском
 	cleanupTestData()
@@ -117,6 +554,66 @@ func cleanupTestData() {
 			log.Printf("Error truncating votes: %v", err)
 		}
 
+		// Очистка пространства reminders
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.reminders:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating reminders: %v", err)
+		}
+
+		// Очистка пространства weights
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.weights:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating weights: %v", err)
+		}
+
+		// Очистка пространства delegations
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.delegations:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating delegations: %v", err)
+		}
+
+		// Очистка пространства answers
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.answers:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating answers: %v", err)
+		}
+
+		// Очистка пространства final_results
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.final_results:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating final_results: %v", err)
+		}
+
+		// Очистка пространства recurrences
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.recurrences:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating recurrences: %v", err)
+		}
+
+		// Очистка пространства templates
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.templates:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating templates: %v", err)
+		}
+
+		// Очистка пространства processed_posts
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.processed_posts:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating processed_posts: %v", err)
+		}
+
+		// Очистка пространства channel_cursors
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.channel_cursors:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating channel_cursors: %v", err)
+		}
+
+		// Очистка пространства quiz_results
+		_, err = conn.Do(tarantool.NewCallRequest("box.space.quiz_results:truncate")).Get()
+		if err != nil {
+			log.Printf("Error truncating quiz_results: %v", err)
+		}
+
 		conn.Close()
 	}
 }